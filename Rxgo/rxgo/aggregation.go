@@ -0,0 +1,481 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+)
+
+// aggregate node implementation of streamOperator.
+// Items are folded one by one into an accumulation created for each subscription, and final emits the result
+type aggOperater struct {
+	seed   func(o *Observable) interface{}
+	opFunc func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (next interface{}, end bool)
+	final  func(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool)
+}
+
+func (agop aggOperater) op(ctx context.Context, o *Observable) {
+	// must hold defintion of flow resourcs here, such as chan etc., that is allocated when connected
+	// this resurces may be changed when operation routine is running.
+	in := o.pred.outflow
+	out := o.outflow
+
+	goFlow(ctx, func() {
+		acc := agop.seed(o)
+		end := false
+		for x := range in {
+			if end {
+				continue
+			}
+			// send an error to stream
+			if e, ok := x.(error); ok {
+				end = o.sendToFlow(ctx, e, out)
+				continue
+			}
+			acc, end = agop.opFunc(ctx, o, acc, x, out)
+		}
+		if !end && agop.final != nil && ctx.Err() == nil {
+			agop.final(ctx, o, acc, out)
+		}
+		o.closeFlow(ctx, out)
+	})
+}
+
+// accumulation of Reduce and Scan
+type accumulation struct {
+	value interface{}
+	has   bool
+}
+
+// Reduce applies the function with `func(acc, x anytype) anytype` to each item sequentially,
+// and emits only the final accumulated value. If seed is nil, the first item is used as seed
+func (parent *Observable) Reduce(f interface{}, seed interface{}) (o *Observable) {
+	o = parent.newTransformObservable("reduce")
+	setAccumulator(o, f)
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return &accumulation{seed, seed != nil}
+		},
+		opFunc: accumulate(false),
+		final: func(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool) {
+			if a := acc.(*accumulation); a.has {
+				end = o.sendToFlow(ctx, a.value, out)
+			}
+			return
+		},
+	}
+	return o
+}
+
+// Scan applies the function with `func(acc, x anytype) anytype` to each item sequentially,
+// and emits each successive accumulated value. If seed is nil, the first item is used as seed
+func (parent *Observable) Scan(f interface{}, seed interface{}) (o *Observable) {
+	o = parent.newTransformObservable("scan")
+	setAccumulator(o, f)
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return &accumulation{seed, seed != nil}
+		},
+		opFunc: accumulate(true),
+	}
+	return o
+}
+
+func setAccumulator(o *Observable, f interface{}) {
+	// check validation of f
+	fv := reflect.ValueOf(f)
+	inType := []reflect.Type{typeAny, typeAny}
+	outType := []reflect.Type{typeAny}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+}
+
+func accumulate(emit bool) func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+	return func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+		a := acc.(*accumulation)
+		if !a.has {
+			a.value, a.has = x, true
+		} else {
+			item, skip, stop := o.callFlip(ctx, []interface{}{a.value, x})
+			if stop {
+				return a, true
+			}
+			if skip {
+				return a, false
+			}
+			if e, ok := item.(error); ok {
+				return a, o.sendToFlow(ctx, e, out)
+			}
+			a.value = item
+		}
+		if emit {
+			return a, o.sendToFlow(ctx, a.value, out)
+		}
+		return a, false
+	}
+}
+
+// Count emits the number of items
+func (parent *Observable) Count() (o *Observable) {
+	o = parent.newTransformObservable("count")
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return 0
+		},
+		opFunc: func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+			return acc.(int) + 1, false
+		},
+		final: sendAccumulation,
+	}
+	return o
+}
+
+func sendAccumulation(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool) {
+	return o.sendToFlow(ctx, acc, out)
+}
+
+// sum of numbers, it has the type of the first number
+type numberSum struct {
+	t reflect.Type
+	i int64
+	u uint64
+	f float64
+	n int
+}
+
+// Sum emits the sum of numbers, which has the type of the first item.
+// An item that is not a number is sent as FlowableError with ErrNotNumber
+func (parent *Observable) Sum() (o *Observable) {
+	o = parent.newTransformObservable("sum")
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return &numberSum{}
+		},
+		opFunc: addNumber,
+		final: func(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool) {
+			s := acc.(*numberSum)
+			if s.n == 0 {
+				return
+			}
+			v := reflect.New(s.t).Elem()
+			switch numberKind(s.t) {
+			case signedNumber:
+				v.SetInt(s.i)
+			case unsignedNumber:
+				v.SetUint(s.u)
+			case floatNumber:
+				v.SetFloat(s.f)
+			}
+			return o.sendToFlow(ctx, v.Interface(), out)
+		},
+	}
+	return o
+}
+
+// Average emits the average of numbers as float64.
+// An item that is not a number is sent as FlowableError with ErrNotNumber
+func (parent *Observable) Average() (o *Observable) {
+	o = parent.newTransformObservable("average")
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return &numberSum{t: reflect.TypeOf(float64(0))}
+		},
+		opFunc: addNumber,
+		final: func(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool) {
+			s := acc.(*numberSum)
+			if s.n == 0 {
+				return
+			}
+			return o.sendToFlow(ctx, s.f/float64(s.n), out)
+		},
+	}
+	return o
+}
+
+func addNumber(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+	s := acc.(*numberSum)
+	v := reflect.ValueOf(x)
+	if !isNumber(v) {
+		return s, o.sendToFlow(ctx, FlowableError{Err: ErrNotNumber, Elements: x}, out)
+	}
+	if s.t == nil {
+		s.t = v.Type()
+	}
+	v = v.Convert(s.t)
+	switch numberKind(s.t) {
+	case signedNumber:
+		s.i += v.Int()
+	case unsignedNumber:
+		s.u += v.Uint()
+	case floatNumber:
+		s.f += v.Float()
+	}
+	s.n++
+	return s, false
+}
+
+// Min emits the smallest number.
+// An item that is not a number is sent as FlowableError with ErrNotNumber
+func (parent *Observable) Min() (o *Observable) {
+	o = parent.newTransformObservable("min")
+	o.operator = extremumOperater(-1)
+	return o
+}
+
+// Max emits the largest number.
+// An item that is not a number is sent as FlowableError with ErrNotNumber
+func (parent *Observable) Max() (o *Observable) {
+	o = parent.newTransformObservable("max")
+	o.operator = extremumOperater(1)
+	return o
+}
+
+// keep the item x that compareNumbers(x, others) == sign
+func extremumOperater(sign int) aggOperater {
+	return aggOperater{
+		seed: func(o *Observable) interface{} {
+			return &accumulation{}
+		},
+		opFunc: func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+			a := acc.(*accumulation)
+			v := reflect.ValueOf(x)
+			if !isNumber(v) {
+				return a, o.sendToFlow(ctx, FlowableError{Err: ErrNotNumber, Elements: x}, out)
+			}
+			if !a.has || compareNumbers(v, reflect.ValueOf(a.value)) == sign {
+				a.value, a.has = x, true
+			}
+			return a, false
+		},
+		final: func(ctx context.Context, o *Observable, acc interface{}, out chan interface{}) (end bool) {
+			if a := acc.(*accumulation); a.has {
+				end = o.sendToFlow(ctx, a.value, out)
+			}
+			return
+		},
+	}
+}
+
+const (
+	notNumber = iota
+	signedNumber
+	unsignedNumber
+	floatNumber
+)
+
+func numberKind(t reflect.Type) int {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return signedNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return unsignedNumber
+	case reflect.Float32, reflect.Float64:
+		return floatNumber
+	}
+	return notNumber
+}
+
+func isNumber(v reflect.Value) bool {
+	return v.IsValid() && numberKind(v.Type()) != notNumber
+}
+
+// compare numbers of any kinds, it returns -1, 0 or 1
+func compareNumbers(a, b reflect.Value) int {
+	ka, kb := numberKind(a.Type()), numberKind(b.Type())
+	switch {
+	case ka == floatNumber || kb == floatNumber:
+		fa, fb := toFloat(a), toFloat(b)
+		return compareOrdered(fa < fb, fa > fb)
+	case ka == unsignedNumber && kb == unsignedNumber:
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	case ka == signedNumber && kb == signedNumber:
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+	case ka == signedNumber: // b is unsigned
+		if a.Int() < 0 {
+			return -1
+		}
+		return compareOrdered(uint64(a.Int()) < b.Uint(), uint64(a.Int()) > b.Uint())
+	default: // a is unsigned, b is signed
+		return -compareNumbers(b, a)
+	}
+}
+
+func toFloat(v reflect.Value) float64 {
+	return v.Convert(reflect.TypeOf(float64(0))).Float()
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// ToSlice emits all items in a []interface{} when completed
+func (parent *Observable) ToSlice() (o *Observable) {
+	o = parent.newTransformObservable("toSlice")
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return []interface{}{}
+		},
+		opFunc: func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+			return append(acc.([]interface{}), x), false
+		},
+		final: sendAccumulation,
+	}
+	return o
+}
+
+// ToMap emits all items in a map[interface{}]interface{} when completed,
+// keys are computed by the function with `func(x anytype) anytype`. A later item replaces the former with same key
+func (parent *Observable) ToMap(keyFn interface{}) (o *Observable) {
+	o = parent.newTransformObservable("toMap")
+	setKeyFunc(o, keyFn)
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return map[interface{}]interface{}{}
+		},
+		opFunc: func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+			m := acc.(map[interface{}]interface{})
+			key, skip, stop := o.callFlip(ctx, []interface{}{x})
+			if e, ok := key.(error); ok {
+				return m, o.sendToFlow(ctx, e, out)
+			}
+			if !skip && !stop {
+				m[key] = x
+			}
+			return m, stop
+		},
+		final: sendAccumulation,
+	}
+	return o
+}
+
+// ToMultiMap emits all items in a map[interface{}][]interface{} when completed,
+// keys are computed by the function with `func(x anytype) anytype`
+func (parent *Observable) ToMultiMap(keyFn interface{}) (o *Observable) {
+	o = parent.newTransformObservable("toMultiMap")
+	setKeyFunc(o, keyFn)
+	o.operator = aggOperater{
+		seed: func(o *Observable) interface{} {
+			return map[interface{}][]interface{}{}
+		},
+		opFunc: func(ctx context.Context, o *Observable, acc interface{}, x interface{}, out chan interface{}) (interface{}, bool) {
+			m := acc.(map[interface{}][]interface{})
+			key, skip, stop := o.callFlip(ctx, []interface{}{x})
+			if e, ok := key.(error); ok {
+				return m, o.sendToFlow(ctx, e, out)
+			}
+			if !skip && !stop {
+				m[key] = append(m[key], x)
+			}
+			return m, stop
+		},
+		final: sendAccumulation,
+	}
+	return o
+}
+
+func setKeyFunc(o *Observable, keyFn interface{}) {
+	// check validation of keyFn
+	fv := reflect.ValueOf(keyFn)
+	inType := []reflect.Type{typeAny}
+	outType := []reflect.Type{typeAny}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+}
+
+// BlockingFirst subscribes the Observable and returns its first item or error.
+// It returns ErrEmptyObservable if the Observable completed without items
+func (o *Observable) BlockingFirst() (x interface{}, e error) {
+	e = ErrEmptyObservable
+	var s *Subscription
+	s = o.subscribe(ObserverMonitor{
+		Next: func(item interface{}) {
+			if e == ErrEmptyObservable {
+				x, e = item, nil
+			}
+			s.Dispose()
+		},
+		Error: func(err error) {
+			if e == ErrEmptyObservable {
+				e = err
+			}
+			s.Dispose()
+		},
+	})
+	// s is set before the observer runs
+	go s.run()
+	s.Wait()
+	return
+}
+
+// BlockingLast subscribes the Observable and returns its last item, or the first error.
+// It returns ErrEmptyObservable if the Observable completed without items
+func (o *Observable) BlockingLast() (x interface{}, e error) {
+	empty := true
+	var s *Subscription
+	s = o.subscribe(ObserverMonitor{
+		Next: func(item interface{}) {
+			if e == nil {
+				x, empty = item, false
+			}
+		},
+		Error: func(err error) {
+			if e == nil {
+				e = err
+			}
+			s.Dispose()
+		},
+	})
+	// s is set before the observer runs
+	go s.run()
+	s.Wait()
+	switch {
+	case e != nil:
+		return nil, e
+	case empty:
+		return nil, ErrEmptyObservable
+	}
+	return
+}
+
+// BlockingSlice subscribes the Observable and returns all its items.
+// It stops at the first error, and returns items before it and the error
+func (o *Observable) BlockingSlice() (items []interface{}, e error) {
+	items = []interface{}{}
+	var s *Subscription
+	s = o.subscribe(ObserverMonitor{
+		Next: func(item interface{}) {
+			if e == nil {
+				items = append(items, item)
+			}
+		},
+		Error: func(err error) {
+			if e == nil {
+				e = err
+			}
+			s.Dispose()
+		},
+	})
+	// s is set before the observer runs
+	go s.run()
+	s.Wait()
+	return
+}
@@ -0,0 +1,143 @@
+package rxgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReduce(t *testing.T) {
+	res := []int{}
+	Range(1, 5).Reduce(func(acc, x int) int {
+		return acc + x
+	}, nil).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{10}, res, "Reduce Test Error!")
+
+	res = []int{}
+	Range(1, 5).Reduce(func(acc, x int) int {
+		return acc * x
+	}, 10).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{240}, res, "Reduce with seed Test Error!")
+
+	res = []int{}
+	Empty().Reduce(func(acc, x int) int {
+		return acc + x
+	}, nil).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{}, res, "Reduce empty Test Error!")
+}
+
+func TestScan(t *testing.T) {
+	res := []int{}
+	Range(1, 5).Scan(func(acc, x int) int {
+		return acc + x
+	}, nil).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 3, 6, 10}, res, "Scan Test Error!")
+
+	res = []int{}
+	Just(1, 2, 3).Scan(func(acc, x int) int {
+		return acc + x
+	}, 10).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{11, 13, 16}, res, "Scan with seed Test Error!")
+}
+
+func TestCount(t *testing.T) {
+	ee := errors.New("Any")
+	res, completed := collectAll(Just(1, ee, 3, 4).Count())
+
+	assert.Equal(t, []interface{}{ee, 3}, res, "Count Test Error!")
+	assert.True(t, completed, "Count Completed Error!")
+
+	res, _ = collectAll(Empty().Count())
+	assert.Equal(t, []interface{}{0}, res, "Count empty Test Error!")
+}
+
+func TestSumAverage(t *testing.T) {
+	res, _ := collectAll(Just(1, 2, 3, 4).Sum())
+	assert.Equal(t, []interface{}{10}, res, "Sum Test Error!")
+
+	res, _ = collectAll(Just(1.5, 2, 3).Sum())
+	assert.Equal(t, []interface{}{6.5}, res, "Sum float Test Error!")
+
+	res, _ = collectAll(Just(1, 2, 3, 4).Average())
+	assert.Equal(t, []interface{}{2.5}, res, "Average Test Error!")
+
+	res, _ = collectAll(Empty().Sum())
+	assert.Equal(t, []interface{}{}, res, "Sum empty Test Error!")
+
+	res, _ = collectAll(Just(1, "a", 2).Sum())
+	assert.Equal(t, []interface{}{FlowableError{Err: ErrNotNumber, Elements: "a"}, 3}, res, "Sum not number Test Error!")
+}
+
+func TestMinMax(t *testing.T) {
+	res, _ := collectAll(Just(3, 1.5, uint(7), -2, 4).Min())
+	assert.Equal(t, []interface{}{-2}, res, "Min Test Error!")
+
+	res, _ = collectAll(Just(3, 1.5, uint(7), -2, 4).Max())
+	assert.Equal(t, []interface{}{uint(7)}, res, "Max Test Error!")
+
+	res, _ = collectAll(Empty().Max())
+	assert.Equal(t, []interface{}{}, res, "Max empty Test Error!")
+}
+
+func TestToSliceToMap(t *testing.T) {
+	res, _ := collectAll(Just(1, 2, 3).ToSlice())
+	assert.Equal(t, []interface{}{[]interface{}{1, 2, 3}}, res, "ToSlice Test Error!")
+
+	res, _ = collectAll(Just("a", "bb", "cc").ToMap(func(s string) int {
+		return len(s)
+	}))
+	assert.Equal(t, []interface{}{map[interface{}]interface{}{1: "a", 2: "cc"}}, res, "ToMap Test Error!")
+
+	res, _ = collectAll(Just("a", "bb", "cc").ToMultiMap(func(s string) int {
+		return len(s)
+	}))
+	assert.Equal(t, []interface{}{map[interface{}][]interface{}{1: {"a"}, 2: {"bb", "cc"}}}, res, "ToMultiMap Test Error!")
+}
+
+func TestBlocking(t *testing.T) {
+	x, e := Range(1, 100).BlockingFirst()
+	assert.Equal(t, 1, x, "BlockingFirst Test Error!")
+	assert.NoError(t, e)
+
+	x, e = Range(1, 100).BlockingLast()
+	assert.Equal(t, 99, x, "BlockingLast Test Error!")
+	assert.NoError(t, e)
+
+	items, e := Just(1, 2, 3).BlockingSlice()
+	assert.Equal(t, []interface{}{1, 2, 3}, items, "BlockingSlice Test Error!")
+	assert.NoError(t, e)
+
+	_, e = Empty().BlockingFirst()
+	assert.Equal(t, ErrEmptyObservable, e, "BlockingFirst empty Test Error!")
+
+	_, e = Empty().BlockingLast()
+	assert.Equal(t, ErrEmptyObservable, e, "BlockingLast empty Test Error!")
+
+	ee := errors.New("Any")
+	items, e = Just(1, 2, ee, 3).BlockingSlice()
+	assert.Equal(t, []interface{}{1, 2}, items, "BlockingSlice error Test Error!")
+	assert.Equal(t, ee, e, "BlockingSlice error Test Error!")
+
+	x, e = Just(1, ee, 3).BlockingLast()
+	assert.Nil(t, x, "BlockingLast error Test Error!")
+	assert.Equal(t, ee, e, "BlockingLast error Test Error!")
+
+	_, e = Just(ee, 1).BlockingFirst()
+	assert.Equal(t, ee, e, "BlockingFirst error Test Error!")
+}
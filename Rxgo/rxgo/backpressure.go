@@ -0,0 +1,201 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// OverflowPolicy decides what OnBackpressureBuffer does when its buffer is full
+type OverflowPolicy uint
+
+const (
+	OverflowDropLatest OverflowPolicy = iota // drop the arriving item
+	OverflowDropOldest                       // drop the oldest item in buffer
+	OverflowError                            // send FlowableError with ErrBackpressureOverflow and stop
+)
+
+// ErrBackpressureOverflow is sent by OnBackpressureBuffer with OverflowError when its buffer is full
+var ErrBackpressureOverflow = errors.New("Backpressure buffer overflow!")
+
+// OnBackpressureDrop drops items arrived when the downstream is not ready to receive, instead of blocking the upstream.
+// The downstream is not ready if the outflow of the Observable is full. Errors are never dropped
+func (parent *Observable) OnBackpressureDrop() (o *Observable) {
+	o = parent.newTransformObservable("onBackpressureDrop")
+	o.operator = backpressureOperater{0, OverflowDropLatest}
+	return o
+}
+
+// OnBackpressureLatest keeps only the latest item arrived when the downstream is not ready to receive,
+// instead of blocking the upstream. Errors are never dropped
+func (parent *Observable) OnBackpressureLatest() (o *Observable) {
+	o = parent.newTransformObservable("onBackpressureLatest")
+	o.operator = backpressureOperater{1, OverflowDropOldest}
+	return o
+}
+
+// OnBackpressureBuffer buffers at most n items arrived when the downstream is not ready to receive,
+// instead of blocking the upstream, and applies the policy when the buffer is full. Errors are never dropped
+func (parent *Observable) OnBackpressureBuffer(n uint, policy OverflowPolicy) (o *Observable) {
+	o = parent.newTransformObservable("onBackpressureBuffer")
+	o.operator = backpressureOperater{n, policy}
+	return o
+}
+
+// backpressure node implementation of streamOperator.
+// A reader goroutine always receives items from upstream, and sends them to downstream at once if possible,
+// or puts them in a bounded queue that a writer goroutine sends to downstream
+type backpressureOperater struct {
+	size   uint
+	policy OverflowPolicy
+}
+
+// items between the reader and writer
+type pressureQueue struct {
+	mu       sync.Mutex
+	items    []interface{}
+	inflight bool // the writer is sending an item
+	closed   bool // the reader exited
+	notify   chan struct{}
+}
+
+func (bop backpressureOperater) op(ctx context.Context, o *Observable) {
+	in := o.pred.outflow
+	out := o.outflow
+	q := &pressureQueue{notify: make(chan struct{}, 1)}
+
+	// reader
+	goFlow(ctx, func() {
+		end := false
+		for x := range in {
+			if end {
+				continue
+			}
+			_, isErr := x.(error)
+			q.mu.Lock()
+			// keep the order of items, only send at once if the writer has nothing to send
+			if !isErr && len(q.items) == 0 && !q.inflight && o.trySend(ctx, x, out) {
+				q.mu.Unlock()
+				continue
+			}
+			switch {
+			case isErr || uint(len(q.items)) < bop.size:
+				q.items = append(q.items, x)
+			case bop.policy == OverflowDropOldest && len(q.items) > 0:
+				q.items = append(q.items[1:], x)
+			case bop.policy == OverflowError:
+				q.items = append(q.items, FlowableError{Err: ErrBackpressureOverflow, Elements: x})
+				end = true
+				// the upstream may never end by itself
+				cancelUpstream(ctx)
+			default: // dropped
+			}
+			q.mu.Unlock()
+			q.wake()
+		}
+		q.mu.Lock()
+		q.closed = true
+		q.mu.Unlock()
+		q.wake()
+	})
+
+	// writer
+	goFlow(ctx, func() {
+		end := false
+		for {
+			q.mu.Lock()
+			if len(q.items) == 0 || end {
+				q.items = nil
+				if q.closed {
+					q.mu.Unlock()
+					o.closeFlow(ctx, out)
+					return
+				}
+				q.mu.Unlock()
+				<-q.notify
+				continue
+			}
+			x := q.items[0]
+			q.items = q.items[1:]
+			q.inflight = true
+			q.mu.Unlock()
+
+			end = o.sendToFlow(ctx, x, out)
+			q.mu.Lock()
+			q.inflight = false
+			q.mu.Unlock()
+		}
+	})
+}
+
+// send an item if out is not full, it is reported like sendToFlow
+func (o *Observable) trySend(ctx context.Context, x interface{}, out chan interface{}) bool {
+	select {
+	case out <- x:
+		o.sent(ctx, x, 0, out)
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *pressureQueue) wake() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// demand of a pull subscription
+type demand struct {
+	mu     sync.Mutex
+	n      uint
+	notify chan struct{}
+}
+
+// SubscribePull connects the Observables like SubscribeAsync, but items are delivered only when requested by
+// Subscription.Request. Until then the upstream blocks, or drops items with OnBackpressure operators
+func (o *Observable) SubscribePull(ob interface{}) *Subscription {
+	s := o.subscribe(ob)
+	s.demand = &demand{notify: make(chan struct{}, 1)}
+	go s.run()
+	return s
+}
+
+// Request allows n more items, including errors, to be delivered to the observer of SubscribePull.
+// It does nothing to other subscriptions
+func (s *Subscription) Request(n uint) {
+	d := s.demand
+	if d == nil || n == 0 {
+		return
+	}
+	d.mu.Lock()
+	d.n += n
+	d.mu.Unlock()
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait for one requested item, it returns false if cancelled
+func (d *demand) acquire(ctx context.Context) bool {
+	for {
+		d.mu.Lock()
+		if d.n > 0 {
+			d.n--
+			d.mu.Unlock()
+			return true
+		}
+		d.mu.Unlock()
+		select {
+		case <-d.notify:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
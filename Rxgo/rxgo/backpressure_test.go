@@ -0,0 +1,184 @@
+package rxgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a source of integers in [0, n), and produced is closed after all sent
+func producer(n int) (o *Observable, produced chan struct{}) {
+	produced = make(chan struct{})
+	o = Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		defer close(produced)
+		time.Sleep(10 * time.Millisecond) // the subscriber is waiting for items
+		for i := 0; i < n; i++ {
+			if send(i) {
+				return
+			}
+		}
+	})
+	return
+}
+
+// collect items of a pull subscription
+type pullObserver struct {
+	mu  sync.Mutex
+	res []interface{}
+}
+
+func (p *pullObserver) subscribe(o *Observable) *Subscription {
+	return o.SubscribePull(ObserverMonitor{
+		Next: func(x interface{}) {
+			p.mu.Lock()
+			p.res = append(p.res, x)
+			p.mu.Unlock()
+		},
+		Error: func(e error) {
+			p.mu.Lock()
+			p.res = append(p.res, e)
+			p.mu.Unlock()
+		},
+	})
+}
+
+func (p *pullObserver) items() []interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]interface{}{}, p.res...)
+}
+
+// wait until n items received
+func (p *pullObserver) waitItems(n int) []interface{} {
+	deadline := time.Now().Add(time.Second)
+	for len(p.items()) < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return p.items()
+}
+
+// the pull subscriber holds the first item, and the downstream is blocked then
+func pullAfterProduced(t *testing.T, o *Observable, produced chan struct{}) []interface{} {
+	var p pullObserver
+	s := p.subscribe(o.SetBufferLen(0))
+	select {
+	case <-produced:
+	case <-time.After(time.Second):
+		t.Fatal("Upstream is blocked by backpressure!")
+	}
+	// the operator has handled the last item
+	waitIdle()
+	s.Request(1000)
+	s.Wait()
+	return p.items()
+}
+
+func TestOnBackpressureDrop(t *testing.T) {
+	source, produced := producer(100)
+	res := pullAfterProduced(t, source.OnBackpressureDrop(), produced)
+
+	assert.Equal(t, []interface{}{0}, res, "OnBackpressureDrop Test Error!")
+}
+
+func TestOnBackpressureLatest(t *testing.T) {
+	source, produced := producer(100)
+	res := pullAfterProduced(t, source.OnBackpressureLatest(), produced)
+
+	assert.True(t, len(res) >= 2 && len(res) <= 3, "OnBackpressureLatest Test Error!")
+	assert.Equal(t, 0, res[0], "OnBackpressureLatest Test Error!")
+	assert.Equal(t, 99, res[len(res)-1], "OnBackpressureLatest keeps the latest Error!")
+}
+
+func TestOnBackpressureBuffer(t *testing.T) {
+	source, produced := producer(100)
+	res := pullAfterProduced(t, source.OnBackpressureBuffer(5, OverflowDropLatest), produced)
+
+	assert.Len(t, res, 7, "OnBackpressureBuffer drop latest Test Error!")
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5}, res[:6], "OnBackpressureBuffer drop latest Test Error!")
+
+	source, produced = producer(100)
+	res = pullAfterProduced(t, source.OnBackpressureBuffer(3, OverflowDropOldest), produced)
+
+	assert.True(t, len(res) >= 4 && len(res) <= 5, "OnBackpressureBuffer drop oldest Test Error!")
+	assert.Equal(t, []interface{}{97, 98, 99}, res[len(res)-3:], "OnBackpressureBuffer drop oldest Test Error!")
+
+	source, produced = producer(100)
+	res = pullAfterProduced(t, source.OnBackpressureBuffer(3, OverflowError), produced)
+
+	last := res[len(res)-1].(FlowableError)
+	assert.Equal(t, ErrBackpressureOverflow, last.Err, "OnBackpressureBuffer error Test Error!")
+	assert.True(t, len(res) <= 6, "OnBackpressureBuffer emits after error!")
+}
+
+func TestOnBackpressureBufferErrorCancels(t *testing.T) {
+	// the overflow error cancels an infinite upstream
+	stopped := make(chan struct{})
+	source := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		defer close(stopped)
+		for i := 0; !send(i); i++ {
+		}
+	})
+	var p pullObserver
+	s := p.subscribe(source.OnBackpressureBuffer(3, OverflowError).SetBufferLen(0))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("OnBackpressureBuffer error does not cancel upstream!")
+	}
+	s.Request(1000)
+	s.Wait()
+
+	res := p.items()
+	last, _ := res[len(res)-1].(FlowableError)
+	assert.Equal(t, ErrBackpressureOverflow, last.Err, "OnBackpressureBuffer error Test Error!")
+	assert.True(t, len(res) <= 6, "OnBackpressureBuffer emits after error!")
+}
+
+func TestBackpressureNotFull(t *testing.T) {
+	// nothing is dropped if the outflow is not full
+	res := []int{}
+	Range(0, 1000).OnBackpressureDrop().SetBufferLen(1000).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Len(t, res, 1000, "OnBackpressureDrop drops items Error!")
+}
+
+func TestSubscribePull(t *testing.T) {
+	var p pullObserver
+	completed := make(chan struct{})
+	s := Range(0, 10).SubscribePull(ObserverMonitor{
+		Next: func(x interface{}) {
+			p.mu.Lock()
+			p.res = append(p.res, x)
+			p.mu.Unlock()
+		},
+		Completed: func() {
+			close(completed)
+		},
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, p.items(), 0, "SubscribePull delivers without request!")
+
+	s.Request(3)
+	assert.Equal(t, []interface{}{0, 1, 2}, p.waitItems(3), "SubscribePull Request Test Error!")
+	time.Sleep(10 * time.Millisecond)
+	assert.Len(t, p.items(), 3, "SubscribePull delivers more than requested!")
+
+	s.Request(100)
+	<-completed
+	s.Wait()
+	assert.Len(t, p.items(), 10, "SubscribePull Test Error!")
+
+	// unsubscribe while waiting for requests
+	var p2 pullObserver
+	s = p2.subscribe(Range(0, 10))
+	s.Request(1)
+	p2.waitItems(1)
+	s.Dispose()
+	s.Wait()
+	assert.Equal(t, []interface{}{0}, p2.items(), "SubscribePull Dispose Test Error!")
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"io"
+)
+
+// Item is an item or an error of an Observable, received from ToChannel
+type Item struct {
+	Value interface{}
+	Err   error
+}
+
+// IsError reports whether the Item is an error
+func (it Item) IsError() bool {
+	return it.Err != nil
+}
+
+// ToChannel subscribes the Observable and returns a channel of its items and errors, which is closed when
+// the Observable completed. Cancel ctx to unsubscribe if the channel is not read to the end
+func (o *Observable) ToChannel(ctx context.Context) <-chan Item {
+	ch := make(chan Item)
+	send := func(it Item) {
+		select {
+		case ch <- it:
+		case <-ctx.Done():
+		}
+	}
+	s := o.SubscribeAsync(ObserverMonitor{
+		Next: func(x interface{}) {
+			send(Item{Value: x})
+		},
+		Error: func(e error) {
+			send(Item{Err: e})
+		},
+		Context: func() context.Context {
+			return ctx
+		},
+	})
+	go func() {
+		s.Wait()
+		close(ch)
+	}()
+	return ch
+}
+
+// FromFunc creates an Observable that emits items returned by calling f again and again.
+// f ends the Observable by returning io.EOF or ErrEoFlow, and skips a call by returning ErrSkipItem.
+// Other errors are sent to stream and end the Observable
+func FromFunc(f func(ctx context.Context) (interface{}, error)) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for ctx.Err() == nil {
+			x, e := f(ctx)
+			switch e {
+			case nil:
+				if send(x) {
+					return
+				}
+			case ErrSkipItem:
+			case io.EOF, ErrEoFlow:
+				return
+			default:
+				send(e)
+				return
+			}
+		}
+	})
+	o.Name = "FromFunc"
+	return o
+}
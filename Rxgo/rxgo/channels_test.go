@@ -0,0 +1,55 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToChannel(t *testing.T) {
+	ee := errors.New("Any")
+	var items []Item
+	for it := range Just(1, ee, 3).ToChannel(context.Background()) {
+		items = append(items, it)
+	}
+	assert.Equal(t, []Item{{Value: 1}, {Err: ee}, {Value: 3}}, items, "ToChannel Test Error!")
+	assert.True(t, items[1].IsError(), "ToChannel Test Error!")
+
+	// cancelled before the end
+	var sent int64
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	for it := range countingSource(&sent).ToChannel(ctx) {
+		assert.Equal(t, n, it.Value, "ToChannel Test Error!")
+		if n++; n == 3 {
+			cancel()
+		}
+	}
+	assert.True(t, n >= 3, "ToChannel cancel Test Error!")
+}
+
+func TestFromFunc(t *testing.T) {
+	i := 0
+	res, completed := collectAll(FromFunc(func(ctx context.Context) (interface{}, error) {
+		i++
+		switch {
+		case i == 2:
+			return nil, ErrSkipItem
+		case i > 4:
+			return nil, io.EOF
+		}
+		return i, nil
+	}))
+	assert.Equal(t, []interface{}{1, 3, 4}, res, "FromFunc Test Error!")
+	assert.True(t, completed, "FromFunc Test Error!")
+
+	ee := errors.New("Any")
+	res, _ = collectAll(FromFunc(func(ctx context.Context) (interface{}, error) {
+		return nil, ee
+	}))
+	assert.Equal(t, []interface{}{ee}, res, "FromFunc Test Error!")
+}
@@ -0,0 +1,298 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock provides time to Observables. All time based generators and operators get time from the Clock
+// in the subscriber context, so that they can run on virtual time in tests
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) ClockTimer
+}
+
+// ClockTimer is a timer created by Clock, like time.Timer
+type ClockTimer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop() bool
+}
+
+// DefaultClock is the Clock used when the subscriber context has no Clock
+var DefaultClock Clock = realClock{}
+
+type clockKey struct{}
+
+// WithClock returns a copy of ctx with the Clock. Observables subscribed with the context run on the Clock
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockOf returns the Clock of the subscriber context
+func ClockOf(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return DefaultClock
+}
+
+// wall clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) ClockTimer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Reset(d time.Duration) {
+	t.t.Reset(d)
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// TestScheduler is a Clock of virtual time, which only moves forward by AdvanceBy or AdvanceTo.
+// Observables subscribed with WithClock(ctx, testScheduler) see timers fire in a fixed order.
+//
+// Goroutines of Observables still run by the Go scheduler. Before each timer fires and before AdvanceTo returns,
+// the TestScheduler waits until all other goroutines are blocked, so that goroutines woken by a timer have sent
+// their items and the receivers of the items have handled them. Goroutines waiting for the wall clock,
+// such as time.Sleep, are blocked ones, and busy goroutines outside the Observables delay AdvanceTo
+type TestScheduler struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*testTimer // active timers
+	seq    int
+}
+
+type testTimer struct {
+	s    *TestScheduler
+	when time.Time
+	seq  int // timers at same time fire in order of creation
+	ch   chan time.Time
+}
+
+// NewTestScheduler creates a TestScheduler starting at the Unix epoch
+func NewTestScheduler() *TestScheduler {
+	return &TestScheduler{now: time.Unix(0, 0)}
+}
+
+func (s *TestScheduler) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+func (s *TestScheduler) NewTimer(d time.Duration) ClockTimer {
+	t := &testTimer{s: s, ch: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// AdvanceBy moves virtual time forward by d, firing timers in order
+func (s *TestScheduler) AdvanceBy(d time.Duration) {
+	s.AdvanceTo(s.Now().Add(d))
+}
+
+// AdvanceTo moves virtual time forward to target, firing timers in order
+func (s *TestScheduler) AdvanceTo(target time.Time) {
+	for {
+		waitIdle()
+		s.mu.Lock()
+		if len(s.timers) == 0 || s.timers[0].when.After(target) {
+			if target.After(s.now) {
+				s.now = target
+			}
+			s.mu.Unlock()
+			waitIdle()
+			return
+		}
+		t := s.timers[0]
+		s.timers = s.timers[1:]
+		if t.when.After(s.now) {
+			s.now = t.when
+		}
+		select {
+		case t.ch <- s.now:
+		default:
+		}
+		s.mu.Unlock()
+	}
+}
+
+// wait until all goroutines except the calling one are blocked
+func waitIdle() {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n == len(buf) {
+			buf = make([]byte, 2*len(buf))
+			continue
+		}
+		if idle(buf[:n]) {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+// states of goroutines that are not blocked
+var activeStates = []string{"running", "runnable", "syscall", "preempted", "copystack"}
+
+// idle reports whether the goroutines in the stack dump are blocked. The first one is the calling goroutine
+func idle(dump []byte) bool {
+	for i, header := range goroutineHeader.FindAllSubmatch(dump, -1) {
+		if i == 0 {
+			continue
+		}
+		for _, state := range activeStates {
+			if string(header[1]) == state {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// `goroutine 7 [chan receive, 2 minutes]:` with the state in the first group
+var goroutineHeader = regexp.MustCompile(`(?m)^goroutine \d+ [^\[\n]*\[([^,\]]+)`)
+
+// must hold s.mu
+func (s *TestScheduler) add(t *testTimer) {
+	s.seq++
+	t.seq = s.seq
+	s.timers = append(s.timers, t)
+	sort.Slice(s.timers, func(i, j int) bool {
+		a, b := s.timers[i], s.timers[j]
+		return a.when.Before(b.when) || a.when.Equal(b.when) && a.seq < b.seq
+	})
+}
+
+// must hold s.mu
+func (s *TestScheduler) remove(t *testTimer) bool {
+	for i, at := range s.timers {
+		if at == t {
+			s.timers = append(s.timers[:i], s.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (t *testTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *testTimer) Reset(d time.Duration) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	t.s.remove(t)
+	select {
+	case <-t.ch: // drop the stale fire
+	default:
+	}
+	t.when = t.s.now.Add(d)
+	t.s.add(t)
+}
+
+func (t *testTimer) Stop() bool {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	return t.s.remove(t)
+}
+
+// Interval creates an Observable that emits a sequence of integers spaced by the period
+func Interval(period time.Duration) *Observable {
+	o := TimerPeriodic(period, period)
+	o.Name = "Interval"
+	return o
+}
+
+// Timer creates an Observable that emits 0 after the delay and then completes
+func Timer(delay time.Duration) *Observable {
+	o := TimerPeriodic(delay, 0)
+	o.Name = "Timer"
+	return o
+}
+
+// TimerPeriodic creates an Observable that emits 0 after the delay, and then emits 1, 2 ... spaced by the period.
+// It completes after the first item if period <= 0
+func TimerPeriodic(delay, period time.Duration) *Observable {
+	o := newGeneratorObservable("TimerPeriodic")
+
+	o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
+		timer := newPeriodTimer(ctx, delay)
+		timer.period = period
+		defer timer.Stop()
+		for i := 0; ; i++ {
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				return
+			}
+			if b := o.sendToFlow(ctx, i, out); b || period <= 0 {
+				return
+			}
+			timer.advance()
+		}
+	}
+	o.operator = timerSource
+	return o
+}
+
+var timerSource = rangeSource
+
+// periodTimer ticks every period on the subscriber clock without drift. It is the timer of time based operators
+type periodTimer struct {
+	clock  Clock
+	timer  ClockTimer
+	period time.Duration
+	next   time.Time // time of the next tick
+}
+
+// newPeriodTimer creates a periodTimer of which the first tick is after d
+func newPeriodTimer(ctx context.Context, d time.Duration) *periodTimer {
+	clock := ClockOf(ctx)
+	return &periodTimer{clock, clock.NewTimer(d), d, clock.Now().Add(d)}
+}
+
+func (t *periodTimer) C() <-chan time.Time {
+	return t.timer.C()
+}
+
+// advance schedules the tick a period after the fired one
+func (t *periodTimer) advance() {
+	t.next = t.next.Add(t.period)
+	t.timer.Reset(t.next.Sub(t.clock.Now()))
+}
+
+// restart schedules the tick a period from now
+func (t *periodTimer) restart() {
+	t.next = t.clock.Now().Add(t.period)
+	t.timer.Reset(t.period)
+}
+
+func (t *periodTimer) Stop() {
+	t.timer.Stop()
+}
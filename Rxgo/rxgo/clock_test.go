@@ -0,0 +1,151 @@
+package rxgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect items of o subscribed on the virtual clock
+type virtualObserver struct {
+	mu  sync.Mutex
+	res []interface{}
+}
+
+func (v *virtualObserver) subscribe(o *Observable, ts *TestScheduler) *Subscription {
+	return o.SubscribeAsync(ObserverMonitor{
+		Next: func(x interface{}) {
+			v.mu.Lock()
+			v.res = append(v.res, x)
+			v.mu.Unlock()
+		},
+		Context: func() context.Context {
+			return WithClock(context.Background(), ts)
+		},
+	})
+}
+
+func (v *virtualObserver) items() []interface{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]interface{}{}, v.res...)
+}
+
+// emit items at virtual time offsets
+func timedSource(items map[time.Duration]interface{}, end time.Duration) *Observable {
+	return Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		clock := ClockOf(ctx)
+		start := clock.Now()
+		keys := []time.Duration{}
+		for d := range items {
+			keys = append(keys, d)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		for _, d := range append(keys, end) {
+			<-clock.NewTimer(start.Add(d).Sub(clock.Now())).C()
+			if d == end {
+				return
+			}
+			if send(items[d]) {
+				return
+			}
+		}
+	})
+}
+
+func TestInterval(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(Interval(10*time.Millisecond), ts)
+
+	ts.AdvanceBy(9 * time.Millisecond)
+	assert.Len(t, v.items(), 0, "Interval emits too early!")
+	ts.AdvanceBy(26 * time.Millisecond)
+	assert.Equal(t, []interface{}{0, 1, 2}, v.items(), "Interval Test Error!")
+
+	s.Dispose()
+	s.Wait()
+}
+
+func TestTimer(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(Timer(time.Second), ts)
+
+	ts.AdvanceBy(999 * time.Millisecond)
+	assert.Len(t, v.items(), 0, "Timer emits too early!")
+	ts.AdvanceBy(time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{0}, v.items(), "Timer Test Error!")
+	assert.Equal(t, time.Unix(1, 0), ts.Now(), "virtual time Error!")
+}
+
+func TestTimerPeriodic(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(TimerPeriodic(50*time.Millisecond, 10*time.Millisecond).Map(func(x int) int {
+		return x * 10
+	}), ts)
+
+	ts.AdvanceBy(80 * time.Millisecond)
+	assert.Equal(t, []interface{}{0, 10, 20, 30}, v.items(), "TimerPeriodic Test Error!")
+
+	s.Dispose()
+	s.Wait()
+}
+
+func TestDebounceVirtual(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := timedSource(map[time.Duration]interface{}{
+		0:                     1,
+		10 * time.Millisecond: 2,
+		40 * time.Millisecond: 3,
+		45 * time.Millisecond: 4,
+	}, 100*time.Millisecond)
+	s := v.subscribe(source.Debounce(20*time.Millisecond), ts)
+
+	ts.AdvanceBy(100 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{2, 4}, v.items(), "Debounce virtual Test Error!")
+}
+
+func TestReplaySubjectVirtualWindow(t *testing.T) {
+	ts := NewTestScheduler()
+	s := NewReplaySubject(0, 30*time.Millisecond).SetClock(ts)
+	s.OnNext(1)
+	ts.AdvanceBy(20 * time.Millisecond)
+	s.OnNext(2)
+	ts.AdvanceBy(20 * time.Millisecond)
+	s.OnNext(3)
+	s.OnCompleted()
+
+	res := []int{}
+	s.Subscribe(func(x int) {
+		res = append(res, x)
+	})
+	assert.Equal(t, []int{2, 3}, res, "ReplaySubject virtual window Test Error!")
+}
+
+func TestTestSchedulerWaitsForReceivers(t *testing.T) {
+	ts := NewTestScheduler()
+	first, second := ts.NewTimer(10*time.Millisecond), ts.NewTimer(20*time.Millisecond)
+	defer second.Stop()
+	// the fire of the first timer is handed on by goroutines, the last one is busy for a while
+	relay, seen := make(chan time.Time), make(chan time.Time, 1)
+	go func() {
+		relay <- <-first.C()
+	}()
+	go func() {
+		<-relay
+		for start := time.Now(); time.Since(start) < 30*time.Millisecond; {
+		}
+		seen <- ts.Now()
+	}()
+	ts.AdvanceBy(30 * time.Millisecond)
+	assert.Equal(t, time.Unix(0, 0).Add(10*time.Millisecond), <-seen, "TestScheduler fired the next timer too early!")
+}
@@ -0,0 +1,281 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// combine node implementation of streamOperator.
+// A combining node is the root of a new chain, and its upstream Observables are in o.sources
+type combOperater struct {
+	opFunc func(ctx context.Context, o *Observable, out chan interface{}) (end bool)
+}
+
+func (cop combOperater) op(ctx context.Context, o *Observable) {
+	// must hold defintion of flow resourcs here, such as chan etc., that is allocated when connected
+	// this resurces may be changed when operation routine is running.
+	out := o.outflow
+
+	goFlow(ctx, func() {
+		// all upstreams share one context, and are cancelled when the combination ends before them
+		cctx, cancel := context.WithCancel(ctx)
+		cop.opFunc(cctx, o, out)
+		cancel()
+		o.closeFlow(ctx, out)
+	})
+}
+
+// Merge combines multiple Observables into one by merging their emissions.
+// It completes when all the Observables completed. It inherits TerminateOnError from any of the Observables,
+// then the first error ends it and cancels the others
+func Merge(obs ...*Observable) *Observable {
+	o := newCombineObservable("Merge", obs)
+	o.inheritErrorPolicy()
+	o.operator = mergeOperater
+	return o
+}
+
+var mergeOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	// a source ending the stream cancels the others
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, so := range o.sources {
+		in := so.connectTail(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := range in {
+				if o.sendToFlow(ctx, x, out) {
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return
+}}
+
+// Concat emits the emissions from Observables one after the other, without interleaving them.
+// The next Observable is connected after the previous one completed. It inherits TerminateOnError from any of the Observables
+func Concat(obs ...*Observable) *Observable {
+	o := newCombineObservable("Concat", obs)
+	o.inheritErrorPolicy()
+	o.operator = concatOperater
+	return o
+}
+
+var concatOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	for _, so := range o.sources {
+		for x := range so.connectTail(ctx) {
+			if o.sendToFlow(ctx, x, out) {
+				return true
+			}
+		}
+	}
+	return
+}}
+
+// Zip combines the items of Observables by the function with `func(x1, x2 ... anytype) anytype`
+// and emits the results in strict sequence. It completes when any Observable completed.
+// Errors from Observables are sent to stream directly. It inherits TerminateOnError from any of the Observables
+func Zip(f interface{}, obs ...*Observable) *Observable {
+	o := newCombineObservable("Zip", obs)
+	o.inheritErrorPolicy()
+	setCombineFunc(o, f)
+	o.operator = zipOperater
+	return o
+}
+
+var zipOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	if len(o.sources) == 0 {
+		return
+	}
+	ins := make([]chan interface{}, len(o.sources))
+	for i, so := range o.sources {
+		ins[i] = so.connectTail(ctx)
+	}
+
+	items := make([]interface{}, len(ins))
+	for {
+		for i, in := range ins {
+			for received := false; !received; {
+				select {
+				case x, ok := <-in:
+					if !ok {
+						return
+					}
+					if e, isErr := x.(error); isErr {
+						if o.sendToFlow(ctx, e, out) {
+							return true
+						}
+						continue
+					}
+					items[i] = x
+					received = true
+				case <-ctx.Done():
+					return true
+				}
+			}
+		}
+		if o.sendCombined(ctx, items, out) {
+			return true
+		}
+	}
+}}
+
+// CombineLatest combines the latest item of each Observable by the function with `func(x1, x2 ... anytype) anytype`
+// whenever any Observable emits, after all of them emitted at least one item.
+// It completes when all the Observables completed, or when any of them completed without emitting an item.
+// Errors from Observables are sent to stream directly. It inherits TerminateOnError from any of the Observables
+func CombineLatest(f interface{}, obs ...*Observable) *Observable {
+	o := newCombineObservable("CombineLatest", obs)
+	o.inheritErrorPolicy()
+	setCombineFunc(o, f)
+	o.operator = combineLatestOperater
+	return o
+}
+
+type indexedItem struct {
+	index     int
+	item      interface{}
+	completed bool // the source of index completed, item is nil
+}
+
+var combineLatestOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	merged := make(chan indexedItem)
+	var wg sync.WaitGroup
+	for i, so := range o.sources {
+		in := so.connectTail(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for x := range in {
+				select {
+				case merged <- indexedItem{index: i, item: x}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case merged <- indexedItem{index: i, completed: true}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	goFlow(ctx, func() {
+		wg.Wait()
+		close(merged)
+	})
+
+	latest := make([]interface{}, len(o.sources))
+	has := make([]bool, len(o.sources))
+	count := 0
+	for x := range merged {
+		if x.completed {
+			// no combination is possible without an item of the source
+			if !has[x.index] {
+				return
+			}
+			continue
+		}
+		if e, ok := x.item.(error); ok {
+			if o.sendToFlow(ctx, e, out) {
+				return true
+			}
+			continue
+		}
+		if !has[x.index] {
+			has[x.index] = true
+			count++
+		}
+		latest[x.index] = x.item
+		if count < len(latest) {
+			continue
+		}
+		if o.sendCombined(ctx, latest, out) {
+			return true
+		}
+	}
+	return
+}}
+
+// check validation of combining function f with one parameter for each source
+func setCombineFunc(o *Observable, f interface{}) {
+	fv := reflect.ValueOf(f)
+	inType := make([]reflect.Type, len(o.sources))
+	for i := range inType {
+		inType[i] = typeAny
+	}
+	outType := []reflect.Type{typeAny}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+}
+
+// call combining function with items and send the result
+func (o *Observable) sendCombined(ctx context.Context, items []interface{}, out chan interface{}) (end bool) {
+	item, skip, stop := o.callFlip(ctx, items)
+	if stop {
+		end = true
+		return
+	}
+	if skip {
+		return
+	}
+	end = o.sendToFlow(ctx, item, out)
+	return
+}
+
+// call flip with items as parameters, nil item is passed as zero value.
+// The result is the error if flip throws a FlowableError
+func (o *Observable) callFlip(ctx context.Context, items []interface{}) (item interface{}, skip, stop bool) {
+	fv := reflect.ValueOf(o.flip)
+	ft := fv.Type()
+	params := make([]reflect.Value, 0, ft.NumIn())
+	if o.flip_sup_ctx {
+		params = append(params, reflect.ValueOf(ctx))
+	}
+	for _, x := range items {
+		if x == nil {
+			params = append(params, reflect.Zero(ft.In(len(params))))
+		} else {
+			params = append(params, reflect.ValueOf(x))
+		}
+	}
+	rs, skip, stop, e := userFuncCall(fv, params)
+	if skip || stop {
+		return
+	}
+	if e != nil {
+		item = e
+	} else {
+		item = rs[0].Interface()
+	}
+	return
+}
+
+func newCombineObservable(name string, sources []*Observable) (o *Observable) {
+	o = newGeneratorObservable(name)
+	o.sources = sources
+	return o
+}
+
+// the combination is chained after each source, so it terminates on errors if any source does
+func (o *Observable) inheritErrorPolicy() {
+	for _, so := range o.sources {
+		if so.error_policy == TerminateOnError {
+			o.error_policy = TerminateOnError
+		}
+	}
+}
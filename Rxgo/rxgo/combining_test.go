@@ -0,0 +1,144 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	res := []int{}
+	Merge(Just(1, 2, 3), Just(4, 5).Map(func(x int) int {
+		return x * 10
+	})).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.ElementsMatch(t, []int{1, 2, 3, 40, 50}, res, "Merge Test Error!")
+}
+
+func TestMergeWithCancel(t *testing.T) {
+	res := []int{}
+	var oberver = ObserverMonitor{}
+	oberver.Next = func(y interface{}) {
+		res = append(res, y.(int))
+		oberver.Unsubscribe()
+	}
+	oberver.Context = func() context.Context {
+		ctx, cancel := context.WithCancel(context.Background())
+		oberver.CancelObservables = cancel
+		return ctx
+	}
+
+	// returns only if Never is cancelled
+	Merge(Just(1), Never()).Subscribe(oberver)
+	assert.Equal(t, []int{1}, res, "Merge cancel failure!")
+}
+
+func TestConcat(t *testing.T) {
+	res := []int{}
+	Concat(Just(1, 2), Empty(), Range(3, 6)).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, res, "Concat Test Error!")
+}
+
+func TestZip(t *testing.T) {
+	res := []string{}
+	Zip(func(x int, s string) string {
+		return fmt.Sprint(s, x)
+	}, Range(1, 10), Just("a", "b", "c")).Subscribe(func(x string) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []string{"a1", "b2", "c3"}, res, "Zip Test Error!")
+}
+
+func TestZipError(t *testing.T) {
+	ee := errors.New("Any")
+	res := []interface{}{}
+	Zip(func(x, y int) int {
+		return x + y
+	}, Just(1, ee, 2), Just(10, 20)).Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			res = append(res, e)
+		},
+	})
+
+	assert.Equal(t, []interface{}{11, ee, 22}, res, "Zip Error Test Error!")
+}
+
+func TestZipEmpty(t *testing.T) {
+	res, completed := collectAll(Zip(func() int { return 1 }))
+	assert.Len(t, res, 0, "Zip empty Test Error!")
+	assert.True(t, completed, "Zip empty Test Error!")
+}
+
+func TestZipFuncError(t *testing.T) {
+	assert.Panics(t, func() {
+		Zip(func(x int) int { return x }, Just(1), Just(2))
+	}, "Zip func check Error!")
+}
+
+func TestCombineLatest(t *testing.T) {
+	res := []int{}
+	late := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		// CombineLatest has received 10 when the others are blocked
+		waitIdle()
+		send(1)
+		send(2)
+		send(3)
+	})
+	CombineLatest(func(x, y int) int {
+		return x + y
+	}, Just(10), late).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{11, 12, 13}, res, "CombineLatest Test Error!")
+}
+
+func TestCombineLatestEmptySource(t *testing.T) {
+	// completes and cancels Never, for no item can be combined
+	res, completed := collectAll(CombineLatest(func(x, y int) int {
+		return x + y
+	}, Empty(), Never()))
+	assert.Len(t, res, 0, "CombineLatest empty source Test Error!")
+	assert.True(t, completed, "CombineLatest empty source Test Error!")
+
+	// a source completed after emitting does not end it
+	res, completed = collectAll(CombineLatest(func(x, y int) int {
+		return x + y
+	}, Just(10), Just(1, 2)))
+	assert.Equal(t, 12, res[len(res)-1], "CombineLatest Test Error!")
+	assert.True(t, completed, "CombineLatest Test Error!")
+}
+
+func TestCombiningErrorPolicy(t *testing.T) {
+	ee := errors.New("Any")
+	add := func(x, y int) int {
+		return x + y
+	}
+	for name, o := range map[string]*Observable{
+		"Merge":         Merge(Just(1, ee, 2).SetErrorPolicy(TerminateOnError), Never()),
+		"CombineLatest": CombineLatest(add, Just(1, ee, 2).SetErrorPolicy(TerminateOnError), Never()),
+	} {
+		// the error ends the combination and cancels Never
+		res, completed := collectAll(o)
+		assert.Contains(t, res, ee, name+" error policy Test Error!")
+		assert.Equal(t, ee, res[len(res)-1], name+" error policy Test Error!")
+		assert.False(t, completed, name+" error policy Test Error!")
+	}
+
+	// errors go on by default
+	res, completed := collectAll(Merge(Just(1, ee, 2), Just(3)))
+	assert.ElementsMatch(t, []interface{}{1, ee, 2, 3}, res, "Merge error policy Test Error!")
+	assert.True(t, completed, "Merge error policy Test Error!")
+}
@@ -0,0 +1,266 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+)
+
+// All emits true if the predicate `func(x anytype) bool` returns true for all items.
+// It emits false and cancels the upstream as soon as an item fails
+func (parent *Observable) All(f interface{}) (o *Observable) {
+	o = parent.newFilterObservable("all", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				pass, end := o.testItem(ctx, x, out)
+				if !pass && !end {
+					o.sendToFlow(ctx, false, out)
+					return true
+				}
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				o.sendToFlow(ctx, true, out)
+			},
+		}
+	})
+	setPredicate(o, f)
+	return o
+}
+
+// Any emits true and cancels the upstream as soon as the predicate `func(x anytype) bool` returns true for an item.
+// It emits false if no item passes
+func (parent *Observable) Any(f interface{}) (o *Observable) {
+	o = parent.newFilterObservable("any", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				pass, end := o.testItem(ctx, x, out)
+				if pass {
+					o.sendToFlow(ctx, true, out)
+					return true
+				}
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				o.sendToFlow(ctx, false, out)
+			},
+		}
+	})
+	setPredicate(o, f)
+	return o
+}
+
+// Contains emits true and cancels the upstream as soon as an item equals to value, or emits false if none
+func (parent *Observable) Contains(value interface{}) (o *Observable) {
+	o = parent.Any(func(x interface{}) bool {
+		return equalItems(x, value)
+	})
+	o.Name = "contains"
+	return o
+}
+
+// IsEmpty emits false and cancels the upstream when an item arrives, or emits true if the Observable has no item
+func (parent *Observable) IsEmpty() (o *Observable) {
+	return parent.newFilterObservable("isEmpty", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				o.sendToFlow(ctx, false, out)
+				return true
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				o.sendToFlow(ctx, true, out)
+			},
+		}
+	})
+}
+
+// DefaultIfEmpty emits items of the Observable, or the value if it has no item
+func (parent *Observable) DefaultIfEmpty(value interface{}) (o *Observable) {
+	return parent.newFilterObservable("defaultIfEmpty", func(ctx context.Context, o *Observable) *filter {
+		empty := true
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				empty = false
+				return o.sendToFlow(ctx, x, out)
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				if empty {
+					o.sendToFlow(ctx, value, out)
+				}
+			},
+		}
+	})
+}
+
+// SwitchIfEmpty emits items of the Observable, or items of the other Observable if it has no item
+func (parent *Observable) SwitchIfEmpty(other *Observable) (o *Observable) {
+	o = parent.newFilterObservable("switchIfEmpty", func(ctx context.Context, o *Observable) *filter {
+		empty := true
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				empty = false
+				return o.sendToFlow(ctx, x, out)
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				if empty {
+					o.forwardAll(ctx, o.sources[0], out)
+				}
+			},
+		}
+	})
+	o.sources = []*Observable{other}
+	return o
+}
+
+// connect the source and send all its items, the source is cancelled if the flow ended
+func (o *Observable) forwardAll(ctx context.Context, source *Observable, out chan interface{}) (end bool) {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for x := range source.connectTail(sctx) {
+		if !end && o.sendToFlow(ctx, x, out) {
+			end = true
+			cancel()
+		}
+	}
+	return
+}
+
+// SequenceEqual emits true if the Observable and the other emit equal items in the same order, otherwise false.
+// Items are compared by the function with `func(a, b anytype) bool`, or by == (reflect.DeepEqual if uncomparable) if eq is nil.
+// It emits false and cancels both as soon as a difference is found. Errors are sent to stream
+func (parent *Observable) SequenceEqual(other *Observable, eq interface{}) (o *Observable) {
+	o = newCombineObservable("sequenceEqual", []*Observable{parent, other})
+	if eq != nil {
+		// check validation of eq
+		fv := reflect.ValueOf(eq)
+		inType := []reflect.Type{typeAny, typeAny}
+		outType := []reflect.Type{typeBool}
+		b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+		if !b {
+			panic(ErrFuncFlip)
+		}
+		o.flip_sup_ctx = ctx_sup
+		o.flip = fv.Interface()
+	}
+	o.operator = sequenceEqualOperater
+	return o
+}
+
+var sequenceEqualOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	a := o.sources[0].connectTail(ctx)
+	b := o.sources[1].connectTail(ctx)
+	// next item of a source, errors are sent to stream
+	next := func(in chan interface{}) (x interface{}, ok bool) {
+		for x = range in {
+			if e, isErr := x.(error); isErr {
+				if end = o.sendToFlow(ctx, e, out); end {
+					return nil, false
+				}
+				continue
+			}
+			return x, true
+		}
+		return nil, false
+	}
+	for {
+		x, okA := next(a)
+		if end {
+			return
+		}
+		y, okB := next(b)
+		if end {
+			return
+		}
+		if !okA || !okB {
+			return o.sendToFlow(ctx, okA == okB, out)
+		}
+		equal := false
+		if o.flip == nil {
+			equal = equalItems(x, y)
+		} else {
+			item, skip, stop := o.callFlip(ctx, []interface{}{x, y})
+			if stop {
+				return true
+			}
+			if skip {
+				continue
+			}
+			if e, isErr := item.(error); isErr {
+				if o.sendToFlow(ctx, e, out) {
+					return true
+				}
+				continue
+			}
+			equal, _ = item.(bool)
+		}
+		if !equal {
+			return o.sendToFlow(ctx, false, out)
+		}
+	}
+}}
+
+// Amb mirrors the first of Observables that emits an item, or an error, or completes. Others are cancelled
+func Amb(obs ...*Observable) *Observable {
+	o := newCombineObservable("Amb", obs)
+	o.operator = ambOperater
+	return o
+}
+
+var ambOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	if len(o.sources) == 0 {
+		return
+	}
+	type firstItem struct {
+		i  int
+		x  interface{}
+		ok bool
+	}
+	firsts := make(chan firstItem, len(o.sources))
+	decided := make(chan struct{})
+	winner := -1
+	chans := make([]chan interface{}, len(o.sources))
+	cancels := make([]context.CancelFunc, len(o.sources))
+	for i, so := range o.sources {
+		sctx, cancel := context.WithCancel(ctx)
+		cancels[i] = cancel
+		chans[i] = so.connectTail(sctx)
+	}
+	for i := range chans {
+		i := i
+		goFlow(ctx, func() {
+			x, ok := <-chans[i]
+			firsts <- firstItem{i, x, ok}
+			<-decided
+			if i != winner {
+				for range chans[i] {
+				}
+			}
+		})
+	}
+
+	first := <-firsts
+	winner = first.i
+	for i, cancel := range cancels {
+		if i != winner {
+			cancel()
+		}
+	}
+	close(decided)
+	defer cancels[winner]()
+
+	if !first.ok {
+		return
+	}
+	if o.sendToFlow(ctx, first.x, out) {
+		return true
+	}
+	for x := range chans[winner] {
+		if o.sendToFlow(ctx, x, out) {
+			return true
+		}
+	}
+	return
+}}
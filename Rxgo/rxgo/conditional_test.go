@@ -0,0 +1,134 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a source of integers from 0 counting how many were sent, it never completes
+func countingSource(sent *int64) *Observable {
+	return Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for i := 0; ; i++ {
+			if send(i) {
+				return
+			}
+			atomic.StoreInt64(sent, int64(i+1))
+		}
+	})
+}
+
+func TestAllAny(t *testing.T) {
+	res, _ := collectAll(Just(2, 4, 6).All(func(x int) bool {
+		return x%2 == 0
+	}))
+	assert.Equal(t, []interface{}{true}, res, "All Test Error!")
+
+	res, _ = collectAll(Just(1, 3, 5).Any(func(x int) bool {
+		return x%2 == 0
+	}))
+	assert.Equal(t, []interface{}{false}, res, "Any Test Error!")
+
+	// short-circuit on an endless source, which is cancelled
+	var sent int64
+	res, completed := collectAll(countingSource(&sent).All(func(x int) bool {
+		return x < 10
+	}))
+	assert.Equal(t, []interface{}{false}, res, "All short-circuit Test Error!")
+	assert.True(t, completed, "All Completed Error!")
+
+	res, _ = collectAll(countingSource(&sent).Any(func(x int) bool {
+		return x == 10
+	}))
+	assert.Equal(t, []interface{}{true}, res, "Any short-circuit Test Error!")
+
+	res, _ = collectAll(Empty().All(func(x int) bool {
+		return false
+	}))
+	assert.Equal(t, []interface{}{true}, res, "All empty Test Error!")
+}
+
+func TestCancelUpstream(t *testing.T) {
+	// the source stops as soon as Contains found the item, before the subscription completed
+	var sent int64
+	var atFound int64
+	countingSource(&sent).Contains(5).Subscribe(func(found bool) {
+		time.Sleep(10 * time.Millisecond)
+		atFound = atomic.LoadInt64(&sent)
+	})
+	assert.True(t, atFound < 5+int64(BufferLen)+2, "Contains does not cancel upstream!")
+}
+
+func TestContainsIsEmpty(t *testing.T) {
+	res, _ := collectAll(Just(1, []int{2}, 3).Contains([]int{2}))
+	assert.Equal(t, []interface{}{true}, res, "Contains Test Error!")
+
+	res, _ = collectAll(Just(1, 3).Contains(2))
+	assert.Equal(t, []interface{}{false}, res, "Contains Test Error!")
+
+	res, _ = collectAll(Empty().IsEmpty())
+	assert.Equal(t, []interface{}{true}, res, "IsEmpty Test Error!")
+
+	res, _ = collectAll(Merge(Never(), Just(1)).IsEmpty())
+	assert.Equal(t, []interface{}{false}, res, "IsEmpty Test Error!")
+}
+
+func TestIfEmpty(t *testing.T) {
+	res, _ := collectAll(Empty().DefaultIfEmpty(9))
+	assert.Equal(t, []interface{}{9}, res, "DefaultIfEmpty Test Error!")
+
+	res, _ = collectAll(Just(1, 2).DefaultIfEmpty(9))
+	assert.Equal(t, []interface{}{1, 2}, res, "DefaultIfEmpty Test Error!")
+
+	res, _ = collectAll(Empty().SwitchIfEmpty(Just(7, 8)))
+	assert.Equal(t, []interface{}{7, 8}, res, "SwitchIfEmpty Test Error!")
+
+	res, _ = collectAll(Just(1).SwitchIfEmpty(Just(7, 8)))
+	assert.Equal(t, []interface{}{1}, res, "SwitchIfEmpty Test Error!")
+}
+
+func TestSequenceEqual(t *testing.T) {
+	res, _ := collectAll(Just(1, 2, 3).SequenceEqual(Range(1, 4), nil))
+	assert.Equal(t, []interface{}{true}, res, "SequenceEqual Test Error!")
+
+	res, _ = collectAll(Just(1, 2).SequenceEqual(Just(1, 2, 3), nil))
+	assert.Equal(t, []interface{}{false}, res, "SequenceEqual length Test Error!")
+
+	res, _ = collectAll(Just("a", "B").SequenceEqual(Just("A", "b"), func(a, b string) bool {
+		return len(a) == len(b)
+	}))
+	assert.Equal(t, []interface{}{true}, res, "SequenceEqual eq Test Error!")
+
+	ee := errors.New("Any")
+	res, _ = collectAll(Just(1, ee, 2).SequenceEqual(Just(1, 2), nil))
+	assert.Equal(t, []interface{}{ee, true}, res, "SequenceEqual error Test Error!")
+
+	// short-circuit on endless sources
+	var sent int64
+	res, _ = collectAll(countingSource(&sent).SequenceEqual(Just(0, 1, 5), nil))
+	assert.Equal(t, []interface{}{false}, res, "SequenceEqual short-circuit Test Error!")
+}
+
+func TestAmb(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	slow := timedSource(map[time.Duration]interface{}{
+		5 * time.Millisecond: "slow",
+	}, 6*time.Millisecond)
+	fast := timedSource(map[time.Duration]interface{}{
+		2 * time.Millisecond: "fast1",
+		7 * time.Millisecond: "fast2",
+	}, 8*time.Millisecond)
+	s := v.subscribe(Amb(slow, fast, Never()), ts)
+	ts.AdvanceBy(10 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{"fast1", "fast2"}, v.items(), "Amb Test Error!")
+
+	res, completed := collectAll(Amb(Never(), Empty()))
+	assert.Equal(t, []interface{}{}, res, "Amb empty Test Error!")
+	assert.True(t, completed, "Amb Completed Error!")
+}
@@ -0,0 +1,224 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"sync"
+)
+
+// A ConnectableObservable shares one subscription of its source with all its observers.
+// It does not begin emitting items when it is subscribed, but only when its Connect method is called.
+// Observers only receive items emitted after they subscribed
+type ConnectableObservable struct {
+	*Observable
+	source *Observable
+
+	mu         sync.Mutex
+	session    *publishSession // observers waiting for or receiving items of current connection
+	connection *Subscription
+	refs       int // subscribers of RefCount
+}
+
+// observers of one connection
+type publishSession struct {
+	observers map[*publishObserver]struct{}
+}
+
+type publishObserver struct {
+	ctx  context.Context
+	ch   chan interface{}
+	done chan struct{} // closed when the observer stops draining ch
+	// push and close are serialized, so that no item is pushed to the closed channel
+	mu     sync.Mutex
+	closed bool
+}
+
+func newPublishObserver(ctx context.Context) *publishObserver {
+	return &publishObserver{ctx: ctx, ch: make(chan interface{}, BufferLen), done: make(chan struct{})}
+}
+
+// Publish converts an ordinary Observable into a ConnectableObservable
+func (parent *Observable) Publish() *ConnectableObservable {
+	c := &ConnectableObservable{
+		source:  parent,
+		session: newPublishSession(),
+	}
+	c.Observable = newGeneratorObservable("Publish")
+	c.operator = multicastOperater{func(ctx context.Context) func(send func(x interface{}) (endSignal bool)) {
+		return c.register(ctx).drain
+	}}
+	return c
+}
+
+// Share returns a new Observable that multicasts the Observable, it is Publish().RefCount()
+func (parent *Observable) Share() *Observable {
+	return parent.Publish().RefCount()
+}
+
+// Connect subscribes the source and emits its items to observers.
+// It returns the current connection if the source has been connected
+func (c *ConnectableObservable) Connect() *Subscription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connect()
+}
+
+func (c *ConnectableObservable) connect() *Subscription {
+	if c.connection != nil {
+		return c.connection
+	}
+	session := c.session
+	s := c.source.SubscribeAsync(ObserverMonitor{
+		Next: func(x interface{}) {
+			c.broadcast(session, x)
+		},
+		Error: func(e error) {
+			c.broadcast(session, e)
+		},
+	})
+	c.connection = s
+	go func() {
+		<-s.Done()
+		c.complete(session)
+	}()
+	return s
+}
+
+// RefCount makes a ConnectableObservable behave like an ordinary Observable.
+// It connects when the first observer subscribes, and disposes the connection when the last observer unsubscribes
+func (c *ConnectableObservable) RefCount() *Observable {
+	o := newGeneratorObservable("RefCount")
+	o.operator = multicastOperater{func(ctx context.Context) func(send func(x interface{}) (endSignal bool)) {
+		ob := c.register(ctx)
+		c.mu.Lock()
+		c.refs++
+		if c.refs == 1 {
+			c.connect()
+		}
+		c.mu.Unlock()
+
+		return func(send func(x interface{}) (endSignal bool)) {
+			ob.drain(send)
+
+			c.mu.Lock()
+			c.refs--
+			if c.refs == 0 && c.connection != nil {
+				// observers subscribed later wait for next connection
+				c.connection.Dispose()
+				c.connection = nil
+				c.session = newPublishSession()
+			}
+			c.mu.Unlock()
+		}
+	}}
+	return o
+}
+
+// source node of Observables multicasting to their observers, such as Publish, RefCount and Subjects.
+// register is called when the Observable is connected, so the observer receives items pushed as soon as
+// it subscribed. It returns the function sending items to the observer in the goroutine of the Observable
+type multicastOperater struct {
+	register func(ctx context.Context) (drain func(send func(x interface{}) (endSignal bool)))
+}
+
+func (mop multicastOperater) op(ctx context.Context, o *Observable) {
+	out := o.outflow
+	drain := mop.register(ctx)
+	goFlow(ctx, func() {
+		drain(func(x interface{}) (endSignal bool) {
+			return o.sendToFlow(ctx, x, out)
+		})
+		o.closeFlow(ctx, out)
+	})
+}
+
+// add an observer to current session
+func (c *ConnectableObservable) register(ctx context.Context) *publishObserver {
+	ob := newPublishObserver(ctx)
+	c.mu.Lock()
+	c.session.observers[ob] = struct{}{}
+	c.mu.Unlock()
+	return ob
+}
+
+// send items of the observer until its channel closed or the observer unsubscribed
+func (ob *publishObserver) drain(send func(x interface{}) (endSignal bool)) {
+	defer close(ob.done)
+	for {
+		select {
+		case x, ok := <-ob.ch:
+			if !ok || send(x) {
+				return
+			}
+		case <-ob.ctx.Done():
+			return
+		}
+	}
+}
+
+// push x to the observer, it returns false if the observer unsubscribed or closed
+func (ob *publishObserver) push(x interface{}) bool {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if ob.closed || ob.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case ob.ch <- x:
+		return true
+	case <-ob.ctx.Done():
+	case <-ob.done:
+	}
+	return false
+}
+
+// close the channel after the items being pushed, the observer completes when it drained them
+func (ob *publishObserver) close() {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+	if !ob.closed {
+		ob.closed = true
+		close(ob.ch)
+	}
+}
+
+func (c *ConnectableObservable) broadcast(session *publishSession, x interface{}) {
+	c.mu.Lock()
+	observers := make([]*publishObserver, 0, len(session.observers))
+	for ob := range session.observers {
+		observers = append(observers, ob)
+	}
+	c.mu.Unlock()
+
+	for _, ob := range observers {
+		if !ob.push(x) {
+			c.mu.Lock()
+			delete(session.observers, ob)
+			c.mu.Unlock()
+		}
+	}
+}
+
+// close the observers of the session, and observers subscribed later wait for next connection
+func (c *ConnectableObservable) complete(session *publishSession) {
+	c.mu.Lock()
+	observers := session.observers
+	session.observers = nil
+	if c.session == session {
+		c.session = newPublishSession()
+		c.connection = nil
+	}
+	c.mu.Unlock()
+
+	// closing waits for items being pushed
+	for ob := range observers {
+		ob.close()
+	}
+}
+
+func newPublishSession() *publishSession {
+	return &publishSession{observers: make(map[*publishObserver]struct{})}
+}
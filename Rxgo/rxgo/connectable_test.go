@@ -0,0 +1,130 @@
+package rxgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	ch := make(chan int)
+	co := From(ch).Publish()
+
+	res1, res2 := []int{}, []int{}
+	s1 := co.SubscribeAsync(func(x int) {
+		res1 = append(res1, x)
+	})
+	s2 := co.SubscribeAsync(func(x int) {
+		res2 = append(res2, x)
+	})
+	co.Connect()
+
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	s1.Wait()
+	s2.Wait()
+
+	assert.Equal(t, []int{1, 2, 3}, res1, "Publish Test Error!")
+	assert.Equal(t, []int{1, 2, 3}, res2, "Publish Test Error!")
+}
+
+func TestPublishLateObserver(t *testing.T) {
+	ch := make(chan int)
+	co := From(ch).Map(func(x int) int {
+		return x * 10
+	}).Publish()
+	connection := co.Connect()
+
+	res1, res2 := []int{}, []int{}
+	s1 := co.SubscribeAsync(func(x int) {
+		res1 = append(res1, x)
+	})
+	time.Sleep(10 * time.Millisecond)
+	ch <- 1
+	ch <- 2
+	time.Sleep(10 * time.Millisecond)
+
+	s2 := co.SubscribeAsync(func(x int) {
+		res2 = append(res2, x)
+	})
+	time.Sleep(10 * time.Millisecond)
+	ch <- 3
+	close(ch)
+	connection.Wait()
+	s1.Wait()
+	s2.Wait()
+
+	assert.Equal(t, []int{10, 20, 30}, res1, "Publish Test Error!")
+	assert.Equal(t, []int{30}, res2, "Publish late observer Test Error!")
+}
+
+func TestShare(t *testing.T) {
+	var mu sync.Mutex
+	connections, cancelled := 0, 0
+	source := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		mu.Lock()
+		connections++
+		mu.Unlock()
+		for i := 0; !send(i); i++ {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		cancelled++
+		mu.Unlock()
+	})
+	shared := source.Share()
+
+	s1 := shared.SubscribeAsync(func(x int) {})
+	s2 := shared.SubscribeAsync(func(x int) {})
+	time.Sleep(20 * time.Millisecond)
+	s1.Dispose()
+	s1.Wait()
+	mu.Lock()
+	assert.Equal(t, []int{1, 0}, []int{connections, cancelled}, "RefCount disconnected too early!")
+	mu.Unlock()
+
+	s2.Dispose()
+	s2.Wait()
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		done := cancelled == 1
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	assert.Equal(t, []int{1, 1}, []int{connections, cancelled}, "RefCount Test Error!")
+	mu.Unlock()
+}
+
+func TestConcurrentSubscribe(t *testing.T) {
+	ob := Just(1, 2, 3).Map(func(x int) int {
+		return x * 2
+	})
+
+	var wg sync.WaitGroup
+	results := make([][]int, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := []int{}
+			ob.Subscribe(func(x int) {
+				res = append(res, x)
+			})
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+
+	for _, res := range results {
+		assert.Equal(t, []int{2, 4, 6}, res, "Concurrent Subscribe Test Error!")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Diagram is the graph of Observables in a chain and the chains of their sources, built by (*Observable).Diagram
+// or (*Subscription).Diagram.
+// It is rendered as text by String, Graphviz DOT by DOT, and JSON by JSON
+type Diagram struct {
+	Nodes []DiagramNode `json:"nodes"`
+	Edges []DiagramEdge `json:"edges"`
+}
+
+// DiagramNode describes an Observable
+type DiagramNode struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // source, transform, filter, combine ...
+	Threading   string `json:"threading"`
+	BufferLen   uint   `json:"bufferLen"`
+	Concurrency uint   `json:"concurrency,omitempty"`
+	Ordered     bool   `json:"ordered,omitempty"`
+	ErrorPolicy string `json:"errorPolicy"`
+	// the Observable subscribes inner Observables created by its function, such as FlatMap and Catch
+	Inner bool `json:"inner,omitempty"`
+	// outflow of a connected Observable, see Subscription.Diagram
+	Outflow  string `json:"outflow,omitempty"`
+	Queued   int    `json:"queued,omitempty"`
+	Capacity int    `json:"capacity,omitempty"`
+}
+
+// DiagramEdge is a flow of items between Observables
+type DiagramEdge struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Kind string `json:"kind"` // "next" in a chain, or "source" from the last Observable of a source chain
+}
+
+// Diagram walks the chain ending with o from its first Observable, and the chains of sources of combining Observables.
+// An Observable shared by chains is one node with many downstream edges
+func (o *Observable) Diagram() *Diagram {
+	d := &Diagram{}
+	d.add(o, make(map[*Observable]int))
+	return d
+}
+
+// Diagram walks the Observables connected by the subscription, annotated with their outflows
+func (s *Subscription) Diagram() *Diagram {
+	return s.last.Diagram()
+}
+
+// add o after its upstream Observables and sources, and return its id
+func (d *Diagram) add(o *Observable, ids map[*Observable]int) int {
+	if id, ok := ids[o]; ok {
+		return id
+	}
+	pred := -1
+	if o.pred != nil {
+		pred = d.add(o.pred, ids)
+	}
+	sources := make([]int, len(o.sources))
+	for i, so := range o.sources {
+		sources[i] = d.add(so, ids)
+	}
+	id := len(d.Nodes)
+	ids[o] = id
+	d.Nodes = append(d.Nodes, describeNode(id, o))
+	if pred >= 0 {
+		d.Edges = append(d.Edges, DiagramEdge{pred, id, "next"})
+	}
+	for _, from := range sources {
+		d.Edges = append(d.Edges, DiagramEdge{from, id, "source"})
+	}
+	return id
+}
+
+func describeNode(id int, o *Observable) DiagramNode {
+	n := DiagramNode{
+		ID:          id,
+		Name:        o.Name,
+		Kind:        operatorKind(o.operator),
+		Threading:   o.threading.String(),
+		BufferLen:   o.buf_len,
+		Concurrency: o.concurrency,
+		Ordered:     o.ordered,
+		ErrorPolicy: o.error_policy.String(),
+	}
+	if o.flip != nil {
+		ft := reflect.TypeOf(o.flip)
+		n.Inner = ft.Kind() == reflect.Func && ft.NumOut() > 0 && ft.Out(0) == typeObservable
+	}
+	if o.outflow != nil {
+		n.Outflow = fmt.Sprintf("%p", o.outflow)
+		n.Queued, n.Capacity = len(o.outflow), cap(o.outflow)
+	}
+	return n
+}
+
+func operatorKind(op streamOperator) string {
+	switch op.(type) {
+	case sourceOperater, multicastOperater:
+		return "source"
+	case transOperater, flatTransOperater:
+		return "transform"
+	case filOperater:
+		return "filter"
+	case aggOperater:
+		return "aggregate"
+	case combOperater:
+		return "combine"
+	case flatOperater:
+		return "flatten"
+	case bufferOperater:
+		return "buffer"
+	case groupOperater:
+		return "group"
+	case backpressureOperater:
+		return "backpressure"
+	case nil:
+		return "none"
+	}
+	return fmt.Sprintf("%T", op)
+}
+
+// attributes of the node in one line
+func (n DiagramNode) attributes() string {
+	attrs := []string{n.Kind, "threading=" + n.Threading, fmt.Sprint("buf=", n.BufferLen)}
+	if n.Concurrency > 0 {
+		attrs = append(attrs, fmt.Sprint("concurrency=", n.Concurrency))
+	}
+	if n.Ordered {
+		attrs = append(attrs, "ordered")
+	}
+	attrs = append(attrs, "errors="+n.ErrorPolicy)
+	if n.Inner {
+		attrs = append(attrs, "inner")
+	}
+	if n.Outflow != "" {
+		attrs = append(attrs, fmt.Sprintf("outflow=%s(%d/%d)", n.Outflow, n.Queued, n.Capacity))
+	}
+	return strings.Join(attrs, " ")
+}
+
+// String renders the diagram as text, one Observable a line from the first one to the last one.
+// Source chains are indented under the Observable they flow into
+func (d *Diagram) String() string {
+	var sb strings.Builder
+	if len(d.Nodes) == 0 {
+		return ""
+	}
+	prev := make(map[int]int)
+	sources := make(map[int][]int)
+	for _, e := range d.Edges {
+		if e.Kind == "next" {
+			prev[e.To] = e.From
+		} else {
+			sources[e.To] = append(sources[e.To], e.From)
+		}
+	}
+	var render func(id int, indent string)
+	render = func(id int, indent string) {
+		chain := []int{id}
+		for from, ok := prev[id]; ok; from, ok = prev[from] {
+			chain = append([]int{from}, chain...)
+		}
+		for _, id := range chain {
+			n := d.Nodes[id]
+			fmt.Fprintf(&sb, "%s%s [%s]\n", indent, n.Name, n.attributes())
+			for _, s := range sources[id] {
+				fmt.Fprintf(&sb, "%s  <- source:\n", indent)
+				render(s, indent+"    ")
+			}
+		}
+	}
+	// the Observable which the diagram is built for is the last node
+	render(len(d.Nodes)-1, "")
+	return sb.String()
+}
+
+// DOT renders the diagram in the Graphviz DOT language
+func (d *Diagram) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph rxgo {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, n := range d.Nodes {
+		fmt.Fprintf(&sb, "\tn%d [label=%q", n.ID, n.Name+"\n"+n.attributes())
+		if n.Inner {
+			sb.WriteString(", peripheries=2")
+		}
+		sb.WriteString("];\n")
+	}
+	for _, e := range d.Edges {
+		if e.Kind == "source" {
+			fmt.Fprintf(&sb, "\tn%d -> n%d [style=dashed, label=\"source\"];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&sb, "\tn%d -> n%d;\n", e.From, e.To)
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// JSON renders the diagram in indented JSON
+func (d *Diagram) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
@@ -0,0 +1,76 @@
+package rxgo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagramText(t *testing.T) {
+	o := Range(0, 3).Map(func(x int) int {
+		return x
+	}).SubscribeOn(ThreadingIO).TakeUntil(Timer(time.Second))
+
+	assert.Equal(t, `Range [source threading=default buf=0 errors=continue]
+map [transform threading=io buf=128 errors=continue]
+takeUntil [filter threading=default buf=128 errors=continue]
+  <- source:
+    Timer [source threading=default buf=0 errors=continue]
+`, o.Diagram().String(), "Diagram Test Error!")
+}
+
+func TestDiagramCombining(t *testing.T) {
+	a := Just(1).FlatMap(func(x int) *Observable {
+		return Just(x)
+	})
+	b := Range(0, 2).SetErrorPolicy(TerminateOnError)
+	o := Merge(a, b).Filter(func(x int) bool {
+		return true
+	})
+	d := o.Diagram()
+
+	data, err := d.JSON()
+	assert.NoError(t, err, "Diagram JSON Test Error!")
+	var decoded Diagram
+	assert.NoError(t, json.Unmarshal(data, &decoded), "Diagram JSON Test Error!")
+	assert.Equal(t, *d, decoded, "Diagram JSON Test Error!")
+
+	names := []string{}
+	for _, n := range d.Nodes {
+		names = append(names, n.Name)
+	}
+	assert.Equal(t, []string{"Just", "flatMap", "Range", "Merge", "filter"}, names, "Diagram Test Error!")
+	assert.True(t, d.Nodes[1].Inner, "Diagram Inner Test Error!")
+	assert.Equal(t, "terminate", d.Nodes[2].ErrorPolicy, "Diagram Test Error!")
+	assert.Equal(t, []DiagramEdge{{0, 1, "next"}, {1, 3, "source"}, {2, 3, "source"}, {3, 4, "next"}}, d.Edges, "Diagram Test Error!")
+
+	dot := d.DOT()
+	assert.True(t, strings.HasPrefix(dot, "digraph rxgo {\n"), "Diagram DOT Test Error!")
+	assert.Contains(t, dot, "\tn3 -> n4;\n", "Diagram DOT Test Error!")
+	assert.Contains(t, dot, "\tn1 -> n3 [style=dashed, label=\"source\"];\n", "Diagram DOT Test Error!")
+	assert.Contains(t, dot, `n1 [label="flatMap\ntransform threading=default buf=128 errors=continue inner", peripheries=2];`, "Diagram DOT Test Error!")
+
+	// Observables are never connected
+	for _, n := range d.Nodes {
+		assert.Equal(t, "", n.Outflow, "Diagram Outflow Test Error!")
+	}
+}
+
+func TestSubscriptionDiagram(t *testing.T) {
+	o := Never().Map(func(x int) int {
+		return x
+	})
+	s := o.SubscribeAsync(func(x int) {})
+	d := s.Diagram()
+	s.Dispose()
+	s.Wait()
+
+	assert.Len(t, d.Nodes, 2, "Subscription Diagram Test Error!")
+	for _, n := range d.Nodes {
+		assert.True(t, strings.HasPrefix(n.Outflow, "0x"), "Subscription Diagram Outflow Test Error!")
+	}
+	assert.Equal(t, 128, d.Nodes[1].Capacity, "Subscription Diagram Outflow Test Error!")
+}
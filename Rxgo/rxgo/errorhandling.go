@@ -0,0 +1,132 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"time"
+)
+
+// RetryFunc decides whether to resubscribe after the attempt-th (from 0) error e, and how long to wait before that
+type RetryFunc func(attempt int, e error) (delay time.Duration, retry bool)
+
+// Retry resubscribes the Observable when it sends an error, at most n times.
+// The error is sent to stream if retries are exhausted
+func (parent *Observable) Retry(n int) (o *Observable) {
+	return parent.RetryWhen(func(attempt int, e error) (time.Duration, bool) {
+		return 0, attempt < n
+	})
+}
+
+// RetryWhen resubscribes the Observable when it sends an error and f returns true, after the delay given by f.
+// The error is sent to stream if f returns false
+func (parent *Observable) RetryWhen(f RetryFunc) (o *Observable) {
+	o = newCombineObservable("retry", []*Observable{parent})
+	o.flip = f
+	o.operator = retryOperater
+	return o
+}
+
+// ExponentialBackoff creates a RetryFunc retrying at most n times, and the delay doubles from base up to max
+func ExponentialBackoff(n int, base, max time.Duration) RetryFunc {
+	return func(attempt int, e error) (time.Duration, bool) {
+		if attempt >= n {
+			return 0, false
+		}
+		delay := base << uint(attempt)
+		if delay > max || delay <= 0 {
+			delay = max
+		}
+		return delay, true
+	}
+}
+
+var retryOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	f := o.flip.(RetryFunc)
+	for attempt := 0; ; attempt++ {
+		e, stop := o.forwardUntilError(ctx, o.sources[0], out)
+		if e == nil || stop {
+			return stop
+		}
+		delay, retry := f(attempt, e)
+		if !retry {
+			return o.sendToFlow(ctx, e, out)
+		}
+		if delay > 0 {
+			timer := ClockOf(ctx).NewTimer(delay)
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				timer.Stop()
+				return true
+			}
+		}
+	}
+}}
+
+// Catch switches to the Observable returned by f when the Observable sends an error
+func (parent *Observable) Catch(f func(e error) *Observable) (o *Observable) {
+	o = newCombineObservable("catch", []*Observable{parent})
+	o.flip = f
+	o.operator = catchOperater
+	return o
+}
+
+// OnErrorResumeNext switches to the next Observable when the Observable sends an error
+func (parent *Observable) OnErrorResumeNext(next *Observable) (o *Observable) {
+	o = parent.Catch(func(e error) *Observable {
+		return next
+	})
+	o.Name = "onErrorResumeNext"
+	return o
+}
+
+// OnErrorReturn emits the value and completes when the Observable sends an error
+func (parent *Observable) OnErrorReturn(value interface{}) (o *Observable) {
+	o = parent.Catch(func(e error) *Observable {
+		return Just(value)
+	})
+	o.Name = "onErrorReturn"
+	return o
+}
+
+var catchOperater = combOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
+	f := o.flip.(func(e error) *Observable)
+	e, stop := o.forwardUntilError(ctx, o.sources[0], out)
+	if e == nil || stop {
+		return stop
+	}
+	next := f(e)
+	if next == nil {
+		return
+	}
+	for x := range next.connectTail(ctx) {
+		if o.sendToFlow(ctx, x, out) {
+			return true
+		}
+	}
+	return
+}}
+
+// connect the source and send its items until it sends an error, the source is cancelled then
+func (o *Observable) forwardUntilError(ctx context.Context, source *Observable, out chan interface{}) (e error, end bool) {
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for x := range source.connectTail(sctx) {
+		if e != nil || end {
+			continue // waiting for the source closed
+		}
+		if err, ok := x.(error); ok {
+			e = err
+			cancel()
+			continue
+		}
+		if o.sendToFlow(ctx, x, out) {
+			end = true
+			cancel()
+		}
+	}
+	return
+}
@@ -0,0 +1,134 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collect items and errors of o
+func collectAll(o *Observable) (res []interface{}, completed bool) {
+	res = []interface{}{}
+	o.Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			res = append(res, e)
+		},
+		Completed: func() {
+			completed = true
+		},
+	})
+	return
+}
+
+// a source fails the first n subscriptions after emitting 1
+func failingSource(n int, ee error) (*Observable, *int) {
+	subscribed := 0
+	return Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		subscribed++
+		send(1)
+		if subscribed <= n {
+			send(ee)
+			send(100) // cancelled by the error
+			return
+		}
+		send(2)
+		send(3)
+	}), &subscribed
+}
+
+func TestRetry(t *testing.T) {
+	ee := errors.New("Any")
+	source, subscribed := failingSource(2, ee)
+	res, _ := collectAll(source.Retry(3))
+
+	assert.Equal(t, []interface{}{1, 1, 1, 2, 3}, res, "Retry Test Error!")
+	assert.Equal(t, 3, *subscribed, "Retry subscribed times Error!")
+}
+
+func TestRetryExhausted(t *testing.T) {
+	ee := errors.New("Any")
+	source, subscribed := failingSource(5, ee)
+	res, completed := collectAll(source.Retry(2))
+
+	assert.Equal(t, []interface{}{1, 1, 1, ee}, res, "Retry exhausted Test Error!")
+	assert.Equal(t, 3, *subscribed, "Retry subscribed times Error!")
+	assert.True(t, completed, "Retry not completed!")
+}
+
+func TestRetryWhen(t *testing.T) {
+	ee := errors.New("Any")
+	source, _ := failingSource(2, ee)
+	start := time.Now()
+	res, _ := collectAll(source.RetryWhen(ExponentialBackoff(3, 10*time.Millisecond, time.Second)))
+
+	assert.Equal(t, []interface{}{1, 1, 1, 2, 3}, res, "RetryWhen Test Error!")
+	assert.True(t, time.Since(start) >= 30*time.Millisecond, "RetryWhen backoff Error!")
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	f := ExponentialBackoff(4, time.Second, 3*time.Second)
+	delays := []time.Duration{}
+	for i := 0; ; i++ {
+		d, ok := f(i, nil)
+		if !ok {
+			break
+		}
+		delays = append(delays, d)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, delays, "ExponentialBackoff Test Error!")
+}
+
+func TestCatch(t *testing.T) {
+	ee := errors.New("Any")
+	var caught error
+	res, _ := collectAll(Just(1, ee, 3).Catch(func(e error) *Observable {
+		caught = e
+		return Just(10, 20)
+	}).Map(func(x int) int {
+		return x + 1
+	}))
+
+	assert.Equal(t, []interface{}{2, 11, 21}, res, "Catch Test Error!")
+	assert.Equal(t, ee, caught, "Catch error Error!")
+}
+
+func TestOnErrorResumeNext(t *testing.T) {
+	ee := errors.New("Any")
+	res, _ := collectAll(Just(1, 2).Map(func(x int) int {
+		if x == 2 {
+			panic(FlowableError{Err: ee})
+		}
+		return x
+	}).OnErrorResumeNext(Range(5, 7)))
+
+	assert.Equal(t, []interface{}{1, 5, 6}, res, "OnErrorResumeNext Test Error!")
+}
+
+func TestOnErrorReturn(t *testing.T) {
+	ee := errors.New("Any")
+	res, completed := collectAll(Just(1, ee, 3).OnErrorReturn(-1))
+
+	assert.Equal(t, []interface{}{1, -1}, res, "OnErrorReturn Test Error!")
+	assert.True(t, completed, "OnErrorReturn not completed!")
+}
+
+func TestErrorPolicy(t *testing.T) {
+	ee := errors.New("Any")
+	double := func(x int) int {
+		return x * 2
+	}
+
+	res, completed := collectAll(Just(1, ee, 3).Map(double))
+	assert.Equal(t, []interface{}{2, ee, 6}, res, "ContinueOnError Test Error!")
+	assert.True(t, completed, "ContinueOnError not completed!")
+
+	res, completed = collectAll(Just(1, ee, 3).SetErrorPolicy(TerminateOnError).Map(double))
+	assert.Equal(t, []interface{}{2, ee}, res, "TerminateOnError Test Error!")
+	assert.False(t, completed, "OnCompleted after OnError!")
+}
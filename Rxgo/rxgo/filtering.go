@@ -3,12 +3,30 @@ package rxgo
 import (
 	"context"
 	"reflect"
-	"sync"
 	"time"
 )
-// filter node implementation of streamOperator
+
+// filter node implementation of streamOperator.
+// Items are filtered one by one on a goroutine, by a filter created for each subscription.
+// Filters keep state across items and emit them in order, so their functions always run on the goroutine of the stream:
+// SubscribeOn, SetConcurrency and the Scheduler of the subscription do not apply to them
 type filOperater struct {
-	opFunc func(ctx context.Context, o *Observable, item reflect.Value, out chan interface{}) (end bool)
+	newFilter func(ctx context.Context, o *Observable) *filter
+}
+
+// filter of one subscription, its state lives in the closures
+type filter struct {
+	next func(ctx context.Context, x interface{}, out chan interface{}) (end bool)
+	// called when the upstream completed, optional
+	complete func(ctx context.Context, out chan interface{})
+	// timer of time based filters, optional
+	ticks <-chan time.Time
+	tick  func(ctx context.Context, out chan interface{}) (end bool)
+	// signal from another Observable, optional
+	signal   <-chan struct{}
+	onSignal func(ctx context.Context, out chan interface{}) (end bool)
+	// release resources, optional
+	stop func()
 }
 
 func (ftop filOperater) op(ctx context.Context, o *Observable) {
@@ -16,234 +34,574 @@ func (ftop filOperater) op(ctx context.Context, o *Observable) {
 	// this resurces may be changed when operation routine is running.
 	in := o.pred.outflow
 	out := o.outflow
-	//fmt.Println(o.name, "operator in/out chan ", in, out)
-	var wg sync.WaitGroup
-	if o.computation{	
-		wg.Add(1)
-		go func(){
-			defer wg.Done()
-			for o.computation{
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(o.timespan):
-					if o.flip!=nil{
-						buffer,_:=o.flip.([]interface{})//通过断言实现类型转换
-						for _,v := range buffer{
-							o.sendToFlow(ctx, v, out)
-						}
-						o.flip=nil
+
+	goFlow(ctx, func() {
+		f := ftop.newFilter(ctx, o)
+		if f.stop != nil {
+			defer f.stop()
+		}
+		end, closed := false, false
+		// close the outflow at once, so that the downstream completes without waiting for the upstream,
+		// and cancel the upstream
+		finish := func() {
+			end = true
+			if !closed {
+				closed = true
+				o.closeFlow(ctx, out)
+				cancelUpstream(ctx)
+			}
+		}
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					if !end && f.complete != nil && ctx.Err() == nil {
+						f.complete(ctx, out)
 					}
+					if !closed {
+						o.closeFlow(ctx, out)
+					}
+					return
 				}
-			}	
-		}()	
-	}
-	go func() {
-		end := false
-		for x := range in {
-			if end {
-				continue
-			}
-			// can not pass a interface as parameter (pointer) to gorountion for it may change its value outside!
-			xv := reflect.ValueOf(x)
-			// send an error to stream if the flip not accept error
-			if e, ok := x.(error); ok && !o.flip_accept_error {
-				o.sendToFlow(ctx, e, out)
-				continue
-			}
-			// scheduler
-			switch threading := o.threading; threading {
-			case ThreadingDefault:
-				if ftop.opFunc(ctx, o, xv, out) {
-					end = true
-				}
-			case ThreadingIO:
-				fallthrough
-			case ThreadingComputing:
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					if ftop.opFunc(ctx, o, xv, out) {
-						end = true
+				if end {
+					continue
+				}
+				// send an error to stream if the flip not accept error
+				if e, isErr := x.(error); isErr && !o.flip_accept_error {
+					if o.sendToFlow(ctx, e, out) {
+						finish()
 					}
-				}()
-			default:
+					continue
+				}
+				if f.next(ctx, x, out) {
+					finish()
+				}
+			case <-f.ticks:
+				if !end && f.tick(ctx, out) {
+					finish()
+				}
+			case <-f.signal:
+				f.signal = nil
+				if !end && f.onSignal(ctx, out) {
+					finish()
+				}
 			}
 		}
-		o.computation=false
-		
-		wg.Wait() //waiting all go-routines completed
-		if o.flip!=nil{
-			buffer,_:=o.flip.([]interface{})//通过断言实现类型转换
-			for _,v := range buffer{
-				o.sendToFlow(ctx, v, out)
-			}
-		}		
-		o.closeFlow(out)
-	}()
+	})
+}
+
+func (parent *Observable) newFilterObservable(name string, newFilter func(ctx context.Context, o *Observable) *filter) (o *Observable) {
+	o = parent.newTransformObservable(name)
+	o.operator = filOperater{newFilter}
+	return o
+}
+
+func setPredicate(o *Observable, f interface{}) {
+	// check validation of f
+	fv := reflect.ValueOf(f)
+	inType := []reflect.Type{typeAny}
+	outType := []reflect.Type{typeBool}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
 }
 
+// test x by the predicate in flip, an error thrown by the predicate is sent to out
+func (o *Observable) testItem(ctx context.Context, x interface{}, out chan interface{}) (pass, end bool) {
+	item, skip, stop := o.callFlip(ctx, []interface{}{x})
+	if stop || skip {
+		return false, stop
+	}
+	if e, ok := item.(error); ok {
+		return false, o.sendToFlow(ctx, e, out)
+	}
+	pass, _ = item.(bool)
+	return
+}
+
+// set of items, uncomparable items are compared by reflect.DeepEqual
+type itemSet struct {
+	keys   map[interface{}]struct{}
+	others []interface{}
+}
 
+// add x to the set, it returns false if x is already in the set
+func (s *itemSet) add(x interface{}) bool {
+	if x == nil || reflect.TypeOf(x).Comparable() {
+		if _, found := s.keys[x]; found {
+			return false
+		}
+		if s.keys == nil {
+			s.keys = make(map[interface{}]struct{})
+		}
+		s.keys[x] = struct{}{}
+		return true
+	}
+	for _, y := range s.others {
+		if reflect.DeepEqual(x, y) {
+			return false
+		}
+	}
+	s.others = append(s.others, x)
+	return true
+}
+
+func equalItems(a, b interface{}) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta == tb && (ta == nil || ta.Comparable()) {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Debounce emits an item only if no other item arrives in timespan
 func (parent *Observable) Debounce(timespan time.Duration) (o *Observable) {
-	o = parent.newTransformObservable("debounce")
-	
-	var latest interface{}
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		latest=x.Interface()
-		go func() {
-			for{
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(timespan):
-					if latest==x.Interface() {
-						o.sendToFlow(ctx, x.Interface(), out)
-						return 
-					}
-				}
-			}
-		}()
-			
-		return
-	}}
+	o = parent.newFilterObservable("debounce", func(ctx context.Context, o *Observable) *filter {
+		var latest interface{}
+		timer := ClockOf(ctx).NewTimer(timespan)
+		timer.Stop()
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				latest = x
+				timer.Reset(timespan)
+				return
+			},
+			ticks: timer.C(),
+			tick: func(ctx context.Context, out chan interface{}) (end bool) {
+				return o.sendToFlow(ctx, latest, out)
+			},
+			stop: func() { timer.Stop() },
+		}
+	})
+	o.timespan = timespan
 	return o
 }
 
+// Distinct emits items that have not been emitted before
 func (parent *Observable) Distinct() (o *Observable) {
-	o = parent.newTransformObservable("distinct")
-
-	var slice []interface{}
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		flag := true
-        for i := range slice{
-            if x.Interface() == slice[i] {
-                flag = false  // 存在重复元素，标识为false
-                break
-            }
-        }
-        if flag {  // 标识为false，不添加进结果
-			o.sendToFlow(ctx, x.Interface(), out)
-            slice = append(slice, x.Interface())
-
-        }
-		return
-	}}
+	return parent.newFilterObservable("distinct", func(ctx context.Context, o *Observable) *filter {
+		var seen itemSet
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if seen.add(x) {
+				end = o.sendToFlow(ctx, x, out)
+			}
+			return
+		}}
+	})
+}
+
+// DistinctBy emits items of which the keys, computed by the function with `func(x anytype) anytype`,
+// have not been emitted before
+func (parent *Observable) DistinctBy(keyFn interface{}) (o *Observable) {
+	o = parent.newFilterObservable("distinctBy", func(ctx context.Context, o *Observable) *filter {
+		var seen itemSet
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			key, skip, stop := o.callFlip(ctx, []interface{}{x})
+			if stop || skip {
+				return stop
+			}
+			if e, ok := key.(error); ok {
+				return o.sendToFlow(ctx, e, out)
+			}
+			if seen.add(key) {
+				end = o.sendToFlow(ctx, x, out)
+			}
+			return
+		}}
+	})
+	setKeyFunc(o, keyFn)
 	return o
 }
 
+// DistinctUntilChanged emits items that are different from their predecessors
+func (parent *Observable) DistinctUntilChanged() (o *Observable) {
+	return parent.newFilterObservable("distinctUntilChanged", func(ctx context.Context, o *Observable) *filter {
+		var prev interface{}
+		has := false
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if !has || !equalItems(prev, x) {
+				end = o.sendToFlow(ctx, x, out)
+			}
+			prev, has = x, true
+			return
+		}}
+	})
+}
+
+// ElementAt emits only the item at index (from 0), or sends ErrIndexOutOfRange if there are not enough items
 func (parent *Observable) ElementAt(index int) (o *Observable) {
-	o = parent.newTransformObservable("elementat")
+	o = parent.elementAt(index, func(ctx context.Context, o *Observable, out chan interface{}) {
+		o.sendToFlow(ctx, ErrIndexOutOfRange, out)
+	})
+	o.Name = "elementAt"
+	return o
+}
 
-	takeCount := 0
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		if takeCount==index {
-			o.sendToFlow(ctx, x.Interface(), out)
-			return true
-		}
-		takeCount++
-		return
-	}}
+// ElementAtOrDefault emits only the item at index (from 0), or the default value if there are not enough items
+func (parent *Observable) ElementAtOrDefault(index int, value interface{}) (o *Observable) {
+	o = parent.elementAt(index, func(ctx context.Context, o *Observable, out chan interface{}) {
+		o.sendToFlow(ctx, value, out)
+	})
+	o.Name = "elementAtOrDefault"
 	return o
 }
 
-func (parent *Observable) First() (o *Observable) {
-	o = parent.newTransformObservable("first")
+func (parent *Observable) elementAt(index int, missing func(ctx context.Context, o *Observable, out chan interface{})) (o *Observable) {
+	return parent.newFilterObservable("elementAt", func(ctx context.Context, o *Observable) *filter {
+		count := 0
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if count == index {
+					o.sendToFlow(ctx, x, out)
+					return true
+				}
+				count++
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				missing(ctx, o, out)
+			},
+		}
+	})
+}
 
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		o.sendToFlow(ctx, x.Interface(), out)
-		return true
-	}}
+// Find emits only the first item that the predicate `func(x anytype) bool` returns true
+func (parent *Observable) Find(f interface{}) (o *Observable) {
+	o = parent.newFilterObservable("find", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			pass, end := o.testItem(ctx, x, out)
+			if pass {
+				o.sendToFlow(ctx, x, out)
+				return true
+			}
+			return
+		}}
+	})
+	setPredicate(o, f)
 	return o
 }
 
+// First emits only the first item, or sends ErrEmptyObservable if there is no item
+func (parent *Observable) First() (o *Observable) {
+	return parent.newFilterObservable("first", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				o.sendToFlow(ctx, x, out)
+				return true
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				o.sendToFlow(ctx, ErrEmptyObservable, out)
+			},
+		}
+	})
+}
 
+// IgnoreElements emits no item but errors
 func (parent *Observable) IgnoreElements() (o *Observable) {
-	o = parent.newTransformObservable("ignoreElements")
-
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		return
-	}}
-	return o
+	return parent.newFilterObservable("ignoreElements", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			return
+		}}
+	})
 }
 
-
+// Last emits only the last item, or sends ErrEmptyObservable if there is no item
 func (parent *Observable) Last() (o *Observable) {
-	o = parent.newTransformObservable("last")
-
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		var slice []interface{}
-		o.flip=append(slice,x.Interface())
-		return 
-	}}
-	return o
+	return parent.newFilterObservable("last", func(ctx context.Context, o *Observable) *filter {
+		var last interface{}
+		has := false
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				last, has = x, true
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				if has {
+					o.sendToFlow(ctx, last, out)
+				} else {
+					o.sendToFlow(ctx, ErrEmptyObservable, out)
+				}
+			},
+		}
+	})
 }
 
-
+// Sample emits the latest item in every timespan if there is a new one, and the pending one when completed
 func (parent *Observable) Sample(timespan time.Duration) (o *Observable) {
-	o = parent.newTransformObservable("sample")
-	o.computation = true
+	o = parent.newFilterObservable("sample", func(ctx context.Context, o *Observable) *filter {
+		var latest interface{}
+		has := false
+		timer := newPeriodTimer(ctx, timespan)
+		emit := func(ctx context.Context, out chan interface{}) (end bool) {
+			if has {
+				has = false
+				end = o.sendToFlow(ctx, latest, out)
+			}
+			return
+		}
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				latest, has = x, true
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				emit(ctx, out)
+			},
+			ticks: timer.C(),
+			tick: func(ctx context.Context, out chan interface{}) (end bool) {
+				timer.advance()
+				return emit(ctx, out)
+			},
+			stop: timer.Stop,
+		}
+	})
 	o.timespan = timespan
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		var slice []interface{}
-		o.flip=append(slice,x.Interface())
-		return
-	}}
 	return o
 }
 
+// Single emits the only item, or sends ErrEmptyObservable if there is no item, or ErrNotSingle if there are more
+func (parent *Observable) Single() (o *Observable) {
+	return parent.newFilterObservable("single", func(ctx context.Context, o *Observable) *filter {
+		var single interface{}
+		has := false
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if has {
+					o.sendToFlow(ctx, ErrNotSingle, out)
+					return true
+				}
+				single, has = x, true
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				if has {
+					o.sendToFlow(ctx, single, out)
+				} else {
+					o.sendToFlow(ctx, ErrEmptyObservable, out)
+				}
+			},
+		}
+	})
+}
+
+// Skip skips the first n items
 func (parent *Observable) Skip(n int) (o *Observable) {
-	o = parent.newTransformObservable("skip")
-	skipCount:=0
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		skipCount++;
-		if skipCount>n{
-			o.sendToFlow(ctx, x.Interface(), out)
-		}
-		return
-	}}
-	return o
+	return parent.newFilterObservable("skip", func(ctx context.Context, o *Observable) *filter {
+		skipCount := 0
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if skipCount < n {
+				skipCount++
+				return
+			}
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
 }
 
+// SkipLast skips the last n items, an item is emitted when n items arrived after it
 func (parent *Observable) SkipLast(n int) (o *Observable) {
-	o = parent.newTransformObservable("skipLast")
-	var slice []interface{}
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		slice=append(slice,x.Interface())
-		if len(slice)>n{
-			o.flip=slice[0:len(slice)-n]
-		} 		
-		return 
-	}}
+	return parent.newFilterObservable("skipLast", func(ctx context.Context, o *Observable) *filter {
+		var queue []interface{}
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			queue = append(queue, x)
+			if len(queue) > n {
+				end = o.sendToFlow(ctx, queue[0], out)
+				queue = queue[1:]
+			}
+			return
+		}}
+	})
+}
+
+// SkipUntil skips items until the other Observable emits an item
+func (parent *Observable) SkipUntil(other *Observable) (o *Observable) {
+	o = parent.newFilterObservable("skipUntil", func(ctx context.Context, o *Observable) *filter {
+		started := false
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if started {
+					end = o.sendToFlow(ctx, x, out)
+				}
+				return
+			},
+			signal: watchSignal(ctx, o.sources[0]),
+			onSignal: func(ctx context.Context, out chan interface{}) (end bool) {
+				started = true
+				return
+			},
+		}
+	})
+	o.sources = []*Observable{other}
 	return o
 }
 
-func (parent *Observable) Take(n int) (o *Observable) {
-	o = parent.newTransformObservable("take")
-	takeCount :=0
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		takeCount++
-		if takeCount>n {return true}
-		o.sendToFlow(ctx, x.Interface(), out)
-		return
-	}}
+// SkipWhile skips items until the predicate `func(x anytype) bool` returns false
+func (parent *Observable) SkipWhile(f interface{}) (o *Observable) {
+	o = parent.newFilterObservable("skipWhile", func(ctx context.Context, o *Observable) *filter {
+		skipping := true
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if skipping {
+				pass, end := o.testItem(ctx, x, out)
+				if pass || end {
+					return end
+				}
+				skipping = false
+			}
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
+	setPredicate(o, f)
 	return o
 }
 
+// Take emits only the first n items
+func (parent *Observable) Take(n int) (o *Observable) {
+	return parent.newFilterObservable("take", func(ctx context.Context, o *Observable) *filter {
+		takeCount := 0
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if takeCount >= n {
+				return true
+			}
+			takeCount++
+			return o.sendToFlow(ctx, x, out) || takeCount == n
+		}}
+	})
+}
+
+// TakeLast emits only the last n items when completed
 func (parent *Observable) TakeLast(n int) (o *Observable) {
-	o = parent.newTransformObservable("takeLast")
-
-	var slice []interface{}
-	o.operator  = filOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
-		slice=append(slice,x.Interface())
-		if len(slice)<n{
-			o.flip=slice
-		}else{
-			o.flip=slice[len(slice)-n:len(slice)]
-		} 		
-		return 
-	}}
+	return parent.newFilterObservable("takeLast", func(ctx context.Context, o *Observable) *filter {
+		var last []interface{}
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if n <= 0 {
+					return
+				}
+				if len(last) == n {
+					last = last[1:]
+				}
+				last = append(last, x)
+				return
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				for _, x := range last {
+					if o.sendToFlow(ctx, x, out) {
+						return
+					}
+				}
+			},
+		}
+	})
+}
+
+// TakeUntil emits items until the other Observable emits an item
+func (parent *Observable) TakeUntil(other *Observable) (o *Observable) {
+	o = parent.newFilterObservable("takeUntil", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				return o.sendToFlow(ctx, x, out)
+			},
+			signal: watchSignal(ctx, o.sources[0]),
+			onSignal: func(ctx context.Context, out chan interface{}) (end bool) {
+				return true
+			},
+		}
+	})
+	o.sources = []*Observable{other}
 	return o
 }
 
+// TakeWhile emits items until the predicate `func(x anytype) bool` returns false
+func (parent *Observable) TakeWhile(f interface{}) (o *Observable) {
+	o = parent.newFilterObservable("takeWhile", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			pass, end := o.testItem(ctx, x, out)
+			if !pass || end {
+				return true
+			}
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
+	setPredicate(o, f)
+	return o
+}
 
+// Throttle emits an item at once if no item was emitted in the last timespan,
+// otherwise emits the latest item when the timespan is over. The pending item is emitted when completed
+func (parent *Observable) Throttle(timespan time.Duration) (o *Observable) {
+	o = parent.newFilterObservable("throttle", func(ctx context.Context, o *Observable) *filter {
+		var latest interface{}
+		has, throttling := false, false
+		timer := ClockOf(ctx).NewTimer(timespan)
+		timer.Stop()
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if throttling {
+					latest, has = x, true
+					return
+				}
+				throttling = true
+				timer.Reset(timespan)
+				return o.sendToFlow(ctx, x, out)
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				if has {
+					o.sendToFlow(ctx, latest, out)
+				}
+			},
+			ticks: timer.C(),
+			tick: func(ctx context.Context, out chan interface{}) (end bool) {
+				if !has {
+					throttling = false
+					return
+				}
+				has = false
+				timer.Reset(timespan)
+				return o.sendToFlow(ctx, latest, out)
+			},
+			stop: func() { timer.Stop() },
+		}
+	})
+	o.timespan = timespan
+	return o
+}
 
+// ThrottleFirst emits an item only if no item was emitted in the last timespan
+func (parent *Observable) ThrottleFirst(timespan time.Duration) (o *Observable) {
+	o = parent.newFilterObservable("throttleFirst", func(ctx context.Context, o *Observable) *filter {
+		clock := ClockOf(ctx)
+		var last time.Time
+		has := false
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if now := clock.Now(); !has || now.Sub(last) >= timespan {
+				last, has = now, true
+				end = o.sendToFlow(ctx, x, out)
+			}
+			return
+		}}
+	})
+	o.timespan = timespan
+	return o
+}
+
+// watchSignal connects the Observable, and returns a channel closed when it emits the first item
+func watchSignal(ctx context.Context, source *Observable) <-chan struct{} {
+	signal := make(chan struct{})
+	goFlow(ctx, func() {
+		sctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		fired := false
+		for x := range source.connectTail(sctx) {
+			if _, isErr := x.(error); !fired && !isErr {
+				fired = true
+				close(signal)
+				cancel()
+			}
+		}
+	})
+	return signal
+}
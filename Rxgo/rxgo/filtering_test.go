@@ -141,3 +141,154 @@ func TestTakeLast(t *testing.T) {
 
 
 
+
+func TestFilterPerSubscription(t *testing.T) {
+	// every subscription has its own state
+	chains := map[string]*Observable{
+		"Distinct": Just(1, 2, 1, 3).Distinct(),
+		"Take":     Just(1, 2, 3).Take(2),
+		"Skip":     Just(1, 2, 3).Skip(1),
+		"Last":     Just(1, 2, 3).Last(),
+		"TakeLast": Just(1, 2, 3).TakeLast(2),
+		"SkipLast": Just(1, 2, 3).SkipLast(1),
+	}
+	for name, o := range chains {
+		first, _ := o.BlockingSlice()
+		second, _ := o.BlockingSlice()
+		assert.Equal(t, first, second, name+" shares state between subscriptions!")
+	}
+
+	// and concurrent subscriptions are race free
+	o := Range(0, 100).Distinct().Take(50)
+	s1 := o.SubscribeAsync(func(x int) {})
+	s2 := o.SubscribeAsync(func(x int) {})
+	s1.Wait()
+	s2.Wait()
+}
+
+func TestDistinctVariants(t *testing.T) {
+	res, _ := Just([]int{1}, []int{2}, []int{1}, map[string]int{"a": 1}, map[string]int{"a": 1}).Distinct().BlockingSlice()
+	assert.Equal(t, []interface{}{[]int{1}, []int{2}, map[string]int{"a": 1}}, res, "Distinct uncomparable Test Error!")
+
+	res, _ = Just("a", "bb", "c", "dd", "eee").DistinctBy(func(s string) int {
+		return len(s)
+	}).BlockingSlice()
+	assert.Equal(t, []interface{}{"a", "bb", "eee"}, res, "DistinctBy Test Error!")
+
+	res, _ = Just(1, 1, 2, 2, 1, []int{3}, []int{3}, 3).DistinctUntilChanged().BlockingSlice()
+	assert.Equal(t, []interface{}{1, 2, 1, []int{3}, 3}, res, "DistinctUntilChanged Test Error!")
+}
+
+func TestTakeSkipWhile(t *testing.T) {
+	res, _ := Just(1, 2, 3, 1, 2).TakeWhile(func(x int) bool {
+		return x < 3
+	}).BlockingSlice()
+	assert.Equal(t, []interface{}{1, 2}, res, "TakeWhile Test Error!")
+
+	res, _ = Just(1, 2, 3, 1, 2).SkipWhile(func(x int) bool {
+		return x < 3
+	}).BlockingSlice()
+	assert.Equal(t, []interface{}{3, 1, 2}, res, "SkipWhile Test Error!")
+
+	// Take completes without waiting for an endless upstream
+	res, _ = Interval(time.Millisecond).Take(3).BlockingSlice()
+	assert.Equal(t, []interface{}{0, 1, 2}, res, "Take endless Test Error!")
+}
+
+func TestTakeSkipUntil(t *testing.T) {
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  1,
+		5 * time.Millisecond:  2,
+		12 * time.Millisecond: 3,
+		15 * time.Millisecond: 4,
+	}, 20*time.Millisecond)
+
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(source.TakeUntil(Timer(10*time.Millisecond)), ts)
+	ts.AdvanceBy(30 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{1, 2}, v.items(), "TakeUntil Test Error!")
+
+	ts = NewTestScheduler()
+	var v2 virtualObserver
+	s = v2.subscribe(source.SkipUntil(Timer(10*time.Millisecond)), ts)
+	ts.AdvanceBy(30 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{3, 4}, v2.items(), "SkipUntil Test Error!")
+
+	// TakeUntil completes even if the source emits nothing
+	res, _ := Never().TakeUntil(Just(0)).BlockingSlice()
+	assert.Equal(t, []interface{}{}, res, "TakeUntil Never Test Error!")
+}
+
+func TestThrottle(t *testing.T) {
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  1,
+		3 * time.Millisecond:  2,
+		5 * time.Millisecond:  3,
+		13 * time.Millisecond: 4,
+		30 * time.Millisecond: 5,
+		32 * time.Millisecond: 6,
+	}, 35*time.Millisecond)
+
+	// 1 at once, 3 at 11ms, 4 at 21ms, 5 at 31ms, and 6 when completed
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(source.Throttle(10*time.Millisecond), ts)
+	ts.AdvanceBy(40 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{1, 3, 4, 5, 6}, v.items(), "Throttle Test Error!")
+
+	ts = NewTestScheduler()
+	var v2 virtualObserver
+	s = v2.subscribe(source.ThrottleFirst(10*time.Millisecond), ts)
+	ts.AdvanceBy(40 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{1, 4, 5}, v2.items(), "ThrottleFirst Test Error!")
+}
+
+func TestSingleFindElementAt(t *testing.T) {
+	res, completed := collectAll(Just(7).Single())
+	assert.Equal(t, []interface{}{7}, res, "Single Test Error!")
+	assert.True(t, completed, "Single Completed Error!")
+
+	res, _ = collectAll(Just(7, 8).Single())
+	assert.Equal(t, []interface{}{ErrNotSingle}, res, "Single more Test Error!")
+
+	res, _ = collectAll(Empty().Single())
+	assert.Equal(t, []interface{}{ErrEmptyObservable}, res, "Single empty Test Error!")
+
+	res, _ = collectAll(Range(0, 1<<30).Find(func(x int) bool {
+		return x > 2 && x%5 == 0
+	}))
+	assert.Equal(t, []interface{}{5}, res, "Find Test Error!")
+
+	res, _ = collectAll(Just(1, 2).ElementAt(5))
+	assert.Equal(t, []interface{}{ErrIndexOutOfRange}, res, "ElementAt out of range Test Error!")
+
+	res, _ = collectAll(Just(1, 2).ElementAtOrDefault(5, 0))
+	assert.Equal(t, []interface{}{0}, res, "ElementAtOrDefault Test Error!")
+
+	res, _ = collectAll(Just(1, 2).ElementAtOrDefault(1, 0))
+	assert.Equal(t, []interface{}{2}, res, "ElementAtOrDefault Test Error!")
+
+	res, _ = collectAll(Empty().First())
+	assert.Equal(t, []interface{}{ErrEmptyObservable}, res, "First empty Test Error!")
+
+	res, _ = collectAll(Empty().Last())
+	assert.Equal(t, []interface{}{ErrEmptyObservable}, res, "Last empty Test Error!")
+}
+
+func TestFilterSubscribeOn(t *testing.T) {
+	// the predicate of a stateful filter runs one by one on the stream goroutine, whatever the ThreadModel is
+	var p concurrencyProbe
+	res, _ := collectAll(Range(0, 20).TakeWhile(func(x int) bool {
+		p.enter()
+		defer p.leave()
+		time.Sleep(time.Millisecond)
+		return x < 10
+	}).SubscribeOn(ThreadingIO))
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, res, "Filter SubscribeOn Test Error!")
+	assert.Equal(t, int32(1), p.max, "Filter SubscribeOn is concurrent!")
+}
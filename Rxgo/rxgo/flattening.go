@@ -0,0 +1,171 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type flatStrategy uint
+
+const (
+	concatMap  flatStrategy = iota // inner Observables one by one
+	mergeMap                       // inner Observables at the same time
+	switchMap                      // cancel the active inner Observable on a new item
+	exhaustMap                     // ignore items while an inner Observable is active
+)
+
+// ConcatMap maps each item to an Observable by the function with `func(x anytype) *Observable`,
+// and emits items of these Observables one after another
+func (parent *Observable) ConcatMap(f interface{}) (o *Observable) {
+	return parent.newFlatObservable("concatMap", f, concatMap)
+}
+
+// MergeMap maps each item to an Observable by the function with `func(x anytype) *Observable`,
+// and emits items of these Observables as they arrive. At most maxConcurrent of them are subscribed at the same time,
+// 0 means no limit
+func (parent *Observable) MergeMap(f interface{}, maxConcurrent uint) (o *Observable) {
+	o = parent.newFlatObservable("mergeMap", f, mergeMap)
+	o.concurrency = maxConcurrent
+	return o
+}
+
+// SwitchMap maps each item to an Observable by the function with `func(x anytype) *Observable`,
+// and emits items of the latest one. The previous Observable is cancelled when an item arrives
+func (parent *Observable) SwitchMap(f interface{}) (o *Observable) {
+	return parent.newFlatObservable("switchMap", f, switchMap)
+}
+
+// ExhaustMap maps each item to an Observable by the function with `func(x anytype) *Observable`,
+// and emits its items. Items arrived before it completed are ignored
+func (parent *Observable) ExhaustMap(f interface{}) (o *Observable) {
+	return parent.newFlatObservable("exhaustMap", f, exhaustMap)
+}
+
+func (parent *Observable) newFlatObservable(name string, f interface{}, strategy flatStrategy) (o *Observable) {
+	// check validation of f
+	fv := reflect.ValueOf(f)
+	inType := []reflect.Type{typeAny}
+	outType := []reflect.Type{typeObservable}
+	b, ctx_sup := checkFuncUpcast(fv, inType, outType, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o = parent.newTransformObservable(name)
+	o.flip_accept_error = checkFuncAcceptError(fv)
+
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	o.operator = flatOperater{strategy}
+	return o
+}
+
+// flatten node implementation of streamOperator.
+// One goroutine dispatches items to inner Observables, each forwarded by its own goroutine with a cancelable context
+type flatOperater struct {
+	strategy flatStrategy
+}
+
+func (fop flatOperater) op(ctx context.Context, o *Observable) {
+	in := o.pred.outflow
+	out := o.outflow
+	// only MergeMap runs inners at the same time, the limiter of others is nil
+	var limit limiter
+	if fop.strategy == mergeMap {
+		limit = newLimiter(o.concurrency)
+	}
+
+	goFlow(ctx, func() {
+		// all inners are cancelled if the stream ended
+		fctx, fcancel := context.WithCancel(ctx)
+		defer fcancel()
+		var wg sync.WaitGroup
+		var end int32
+		stop := func() {
+			atomic.StoreInt32(&end, 1)
+			fcancel()
+		}
+		var cancelActive context.CancelFunc
+		var activeDone chan struct{} // closed when the active inner completed
+
+		for x := range in {
+			if atomic.LoadInt32(&end) == 1 {
+				continue
+			}
+			// send an error to stream if the flip not accept error
+			if e, ok := x.(error); ok && !o.flip_accept_error {
+				if o.sendToFlow(ctx, e, out) {
+					stop()
+				}
+				continue
+			}
+			if fop.strategy == exhaustMap && activeDone != nil {
+				select {
+				case <-activeDone:
+				default:
+					continue // the active inner is not completed
+				}
+			}
+			item, skip, halt := o.callFlip(ctx, []interface{}{x})
+			if halt {
+				stop()
+				continue
+			}
+			if skip {
+				continue
+			}
+			if e, ok := item.(error); ok {
+				if o.sendToFlow(ctx, e, out) {
+					stop()
+				}
+				continue
+			}
+			inner, _ := item.(*Observable)
+			if inner == nil {
+				continue
+			}
+
+			switch fop.strategy {
+			case concatMap:
+				if o.forwardAll(fctx, inner, out) && ctx.Err() == nil {
+					stop()
+				}
+				continue
+			case switchMap:
+				if cancelActive != nil {
+					// no item of the previous inner is emitted after switched
+					cancelActive()
+					<-activeDone
+				}
+			case mergeMap:
+				if !limit.acquire(fctx) {
+					stop()
+					continue
+				}
+			}
+			ictx, cancel := context.WithCancel(fctx)
+			done := make(chan struct{})
+			cancelActive, activeDone = cancel, done
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer close(done)
+				defer cancel()
+				defer limit.release()
+				// ended by the downstream, not cancelled
+				if o.forwardAll(ictx, inner, out) && ictx.Err() == nil {
+					stop()
+				}
+			}()
+		}
+
+		wg.Wait() //waiting all inners completed
+		o.closeFlow(ctx, out)
+	})
+}
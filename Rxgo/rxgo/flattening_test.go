@@ -0,0 +1,146 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// outer items at 0, 5 and 20ms, each mapped to a label emitted 10ms later
+func flattenInVirtualTime(flatten func(o *Observable, f interface{}) *Observable) []interface{} {
+	ts := NewTestScheduler()
+	source := timedSource(map[time.Duration]interface{}{
+		0:                     "a",
+		5 * time.Millisecond:  "b",
+		20 * time.Millisecond: "c",
+	}, 40*time.Millisecond)
+	v := &virtualObserver{}
+	s := v.subscribe(flatten(source, func(x string) *Observable {
+		return Timer(10 * time.Millisecond).Map(func(int) string {
+			return x
+		})
+	}), ts)
+	ts.AdvanceBy(50 * time.Millisecond)
+	s.Wait()
+	return v.items()
+}
+
+func TestConcatMap(t *testing.T) {
+	res, completed := collectAll(Just(1, 2, 3).ConcatMap(func(x int) *Observable {
+		return Just(x*10, x*10+1)
+	}))
+	assert.Equal(t, []interface{}{10, 11, 20, 21, 30, 31}, res, "ConcatMap Test Error!")
+	assert.True(t, completed, "ConcatMap Test Error!")
+
+	res = flattenInVirtualTime(func(o *Observable, f interface{}) *Observable {
+		return o.ConcatMap(f)
+	})
+	assert.Equal(t, []interface{}{"a", "b", "c"}, res, "ConcatMap Test Error!")
+}
+
+func TestFlattenSetConcurrency(t *testing.T) {
+	// the concurrency limit only applies to MergeMap
+	flatten := map[string]func(o *Observable, f interface{}) *Observable{
+		"ConcatMap":  (*Observable).ConcatMap,
+		"SwitchMap":  (*Observable).SwitchMap,
+		"ExhaustMap": (*Observable).ExhaustMap,
+	}
+	for name, op := range flatten {
+		done := make(chan bool)
+		go func() {
+			_, completed := collectAll(op(Just(1, 2, 3), func(x int) *Observable {
+				return Just(x)
+			}).SetConcurrency(2))
+			done <- completed
+		}()
+		select {
+		case completed := <-done:
+			assert.True(t, completed, name+" SetConcurrency Test Error!")
+		case <-time.After(time.Second):
+			t.Fatal(name + " with SetConcurrency blocked!")
+		}
+	}
+
+	res, _ := collectAll(Just(1, 2, 3).ConcatMap(func(x int) *Observable {
+		return Just(x, x*10)
+	}).SetConcurrency(1))
+	assert.Equal(t, []interface{}{1, 10, 2, 20, 3, 30}, res, "ConcatMap SetConcurrency Test Error!")
+}
+
+func TestMergeMap(t *testing.T) {
+	res := flattenInVirtualTime(func(o *Observable, f interface{}) *Observable {
+		return o.MergeMap(f, 0)
+	})
+	assert.Equal(t, []interface{}{"a", "b", "c"}, res, "MergeMap Test Error!")
+
+	// at most 2 inners subscribed at the same time
+	var active, most int32
+	res, completed := collectAll(Range(0, 6).MergeMap(func(x int) *Observable {
+		return Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+			n := atomic.AddInt32(&active, 1)
+			for m := atomic.LoadInt32(&most); n > m && !atomic.CompareAndSwapInt32(&most, m, n); m = atomic.LoadInt32(&most) {
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			send(x)
+		})
+	}, 2))
+	sort.Slice(res, func(i, j int) bool { return res[i].(int) < res[j].(int) })
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4, 5}, res, "MergeMap Test Error!")
+	assert.True(t, completed, "MergeMap Test Error!")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&most), "MergeMap Test Error!")
+}
+
+func TestMergeMapCancelWaiting(t *testing.T) {
+	// the next inner waits for the slot of the first one, and is never subscribed after the stream ended
+	var subscribed int32
+	res, completed := collectAll(Just(1, 2, 3).MergeMap(func(x int) *Observable {
+		return Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+			atomic.AddInt32(&subscribed, 1)
+			if !send(x) {
+				<-ctx.Done()
+			}
+		})
+	}, 1).Take(1))
+	assert.Equal(t, []interface{}{1}, res, "MergeMap cancel Test Error!")
+	assert.True(t, completed, "MergeMap cancel Test Error!")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&subscribed), "MergeMap cancel Test Error!")
+}
+
+func TestSwitchMap(t *testing.T) {
+	// the inner of "a" is cancelled at 5ms
+	res := flattenInVirtualTime(func(o *Observable, f interface{}) *Observable {
+		return o.SwitchMap(f)
+	})
+	assert.Equal(t, []interface{}{"b", "c"}, res, "SwitchMap Test Error!")
+}
+
+func TestExhaustMap(t *testing.T) {
+	// "b" arrives while the inner of "a" is active
+	res := flattenInVirtualTime(func(o *Observable, f interface{}) *Observable {
+		return o.ExhaustMap(f)
+	})
+	assert.Equal(t, []interface{}{"a", "c"}, res, "ExhaustMap Test Error!")
+}
+
+func TestFlattenCancelInner(t *testing.T) {
+	// endless inners are cancelled when the downstream completed
+	var sent int64
+	res, completed := collectAll(Just(1).MergeMap(func(x int) *Observable {
+		return countingSource(&sent)
+	}, 0).Take(3))
+	assert.Equal(t, []interface{}{0, 1, 2}, res, "FlattenCancelInner Test Error!")
+	assert.True(t, completed, "FlattenCancelInner Test Error!")
+
+	// errors of the outer Observable are sent to stream
+	ee := errors.New("Any")
+	res, _ = collectAll(Just(1, ee, 3).ConcatMap(func(x int) *Observable {
+		return Just(x)
+	}))
+	assert.Equal(t, []interface{}{1, ee, 3}, res, "FlattenCancelInner Test Error!")
+}
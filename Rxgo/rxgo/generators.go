@@ -21,12 +21,12 @@ func (sop sourceOperater) op(ctx context.Context, o *Observable) {
 	//fmt.Println(o.name, "source out chan ", out)
 
 	// Scheduler
-	go func() {
+	goFlow(ctx, func() {
 		for end := false; !end; { // made panic op re-enter
 			end = sop.opFunc(ctx, o, out)
 		}
-		o.closeFlow(out)
-	}()
+		o.closeFlow(ctx, out)
+	})
 }
 
 func Generator(sf sourceFunc) *Observable {
@@ -103,7 +103,7 @@ var startSource = sourceOperater{func(ctx context.Context, o *Observable, out ch
 func Range(start, end int) *Observable {
 	o := newGeneratorObservable("Range")
 
-	o.flip = func(ctx context.Context, out chan interface{}) {
+	o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 		i := start
 		for i < end {
 			if b := o.sendToFlow(ctx, i, out); b {
@@ -118,7 +118,7 @@ func Range(start, end int) *Observable {
 
 var rangeSource = sourceOperater{func(ctx context.Context, o *Observable, out chan interface{}) (end bool) {
 	fv := reflect.ValueOf(o.flip)
-	params := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(out)}
+	params := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(o), reflect.ValueOf(out)}
 	fv.Call(params)
 	return true
 }}
@@ -127,7 +127,7 @@ var rangeSource = sourceOperater{func(ctx context.Context, o *Observable, out ch
 func Just(items ...interface{}) *Observable {
 	o := newGeneratorObservable("Just")
 
-	o.flip = func(ctx context.Context, out chan interface{}) {
+	o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 		for _, item := range items {
 			if b := o.sendToFlow(ctx, item, out); b {
 				return
@@ -151,7 +151,7 @@ func From(items interface{}) *Observable {
 		length := v.Len()
 		o := newGeneratorObservable("From Slice")
 
-		o.flip = func(ctx context.Context, out chan interface{}) {
+		o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 			i := 0
 			for i < length {
 				item := v.Index(i).Interface()
@@ -168,7 +168,7 @@ func From(items interface{}) *Observable {
 	if v.Kind() == reflect.Chan {
 		o := newGeneratorObservable("From Channel")
 
-		o.flip = func(ctx context.Context, out chan interface{}) {
+		o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 			for {
 				// details: https://godoc.org/reflect#Select
 				var selectcases = []reflect.SelectCase{
@@ -199,14 +199,8 @@ func From(items interface{}) *Observable {
 	if t == st {
 		o := newGeneratorObservable("From *Observable")
 
-		o.flip = func(ctx context.Context, out chan interface{}) {
-			ro := v.Interface().(*Observable)
-			for ; ro.next != nil; ro = ro.next {
-			}
-			ro.mu.Lock()
-			ro.connect(ctx)
-			ch := ro.outflow
-			ro.mu.Unlock()
+		o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
+			ch := v.Interface().(*Observable).connectTail(ctx)
 			for item := range ch {
 				if b := o.sendToFlow(ctx, item, out); b {
 					return
@@ -214,6 +208,7 @@ func From(items interface{}) *Observable {
 			}
 		}
 		o.operator = fromObservable
+		o.sources = []*Observable{v.Interface().(*Observable)}
 		return o
 	}
 
@@ -240,7 +235,7 @@ var throwSource = rangeSource
 func Empty() *Observable {
 	o := newGeneratorObservable("Empty")
 
-	o.flip = func(ctx context.Context, out chan interface{}) {
+	o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 	}
 	o.operator = emptySource
 	return o
@@ -250,7 +245,7 @@ func Empty() *Observable {
 func Throw(e error) *Observable {
 	o := newGeneratorObservable("Throw")
 
-	o.flip = func(ctx context.Context, out chan interface{}) {
+	o.flip = func(ctx context.Context, o *Observable, out chan interface{}) {
 		item := e
 		o.sendToFlow(ctx, item, out)
 	}
@@ -263,9 +258,6 @@ func newGeneratorObservable(name string) (o *Observable) {
 	o = newObservable()
 	o.Name = name
 
-	//chain Observables
-	o.root = o
-
 	//set options
 	o.buf_len = 0
 	return o
@@ -0,0 +1,194 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// GroupBy sends it in a FlowableError with the item if the key of the item is not comparable, such as a slice
+var ErrUncomparableKey = errors.New("Key is not comparable!")
+
+// A GroupedObservable emits the items of one key from GroupBy.
+// It can be subscribed only once, and items are dropped after its subscriber unsubscribed.
+// Every group must be subscribed, or GroupBy blocks when the buffer of the group is full
+type GroupedObservable struct {
+	*Observable
+	key interface{}
+}
+
+// Key returns the key of items in the group
+func (g *GroupedObservable) Key() interface{} {
+	return g.key
+}
+
+// one group under filling
+type group struct {
+	*GroupedObservable
+	ch   chan interface{}
+	done chan struct{} // closed when the subscriber of the group left
+	last time.Time     // time of the latest item
+}
+
+func newGroup(key interface{}, size uint) *group {
+	g := &group{
+		ch:   make(chan interface{}, size),
+		done: make(chan struct{}),
+	}
+	var once sync.Once
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		defer once.Do(func() { close(g.done) })
+		for {
+			select {
+			case x, ok := <-g.ch:
+				if !ok || send(x) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	o.Name = "group"
+	g.GroupedObservable = &GroupedObservable{o, key}
+	return g
+}
+
+// push x to the group, it returns false if cancelled
+func (g *group) push(ctx context.Context, x interface{}) bool {
+	select {
+	case g.ch <- x:
+	case <-g.done:
+	case <-ctx.Done():
+		return false
+	}
+	return true
+}
+
+// GroupBy divides items into groups by keys computed by the function with `func(x anytype) anytype`,
+// and emits each group as a *GroupedObservable when its first item arrives.
+// Keys must be comparable, an item of which the key is a slice, map or func is sent as FlowableError with ErrUncomparableKey.
+// Each group buffers BufferLen items and never expires
+func (parent *Observable) GroupBy(keyFn interface{}) (o *Observable) {
+	o = parent.GroupByWith(keyFn, 0, 0)
+	o.Name = "groupBy"
+	return o
+}
+
+// GroupByWith is like GroupBy, but each group buffers size items (0 means BufferLen),
+// and a group completes if it has no item in expiry (0 means never). A later item of the key starts a new group
+func (parent *Observable) GroupByWith(keyFn interface{}, size uint, expiry time.Duration) (o *Observable) {
+	if size == 0 {
+		size = BufferLen
+	}
+	o = parent.newTransformObservable("groupByWith")
+	setKeyFunc(o, keyFn)
+	o.timespan = expiry
+	o.operator = groupOperater{size}
+	return o
+}
+
+// group node implementation of streamOperator.
+// One goroutine dispatches items to groups, and expires idle groups with a single timer
+type groupOperater struct {
+	size uint
+}
+
+func (gop groupOperater) op(ctx context.Context, o *Observable) {
+	in := o.pred.outflow
+	out := o.outflow
+
+	goFlow(ctx, func() {
+		groups := make(map[interface{}]*group)
+		defer func() {
+			for _, g := range groups {
+				close(g.ch)
+			}
+		}()
+
+		clock := ClockOf(ctx)
+		var expires <-chan time.Time
+		var timer ClockTimer
+		if o.timespan > 0 {
+			timer = clock.NewTimer(o.timespan)
+			timer.Stop()
+			defer timer.Stop()
+			expires = timer.C()
+		}
+		armed := false
+
+		end := false
+		for {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					o.closeFlow(ctx, out)
+					return
+				}
+				if end {
+					continue
+				}
+				if e, isErr := x.(error); isErr {
+					end = o.sendToFlow(ctx, e, out)
+					continue
+				}
+				key, skip, stop := o.callFlip(ctx, []interface{}{x})
+				if stop {
+					end = true
+					continue
+				}
+				if skip {
+					continue
+				}
+				if e, isErr := key.(error); isErr {
+					end = o.sendToFlow(ctx, e, out)
+					continue
+				}
+				if key != nil && !reflect.ValueOf(key).Comparable() {
+					end = o.sendToFlow(ctx, FlowableError{Err: ErrUncomparableKey, Elements: x}, out)
+					continue
+				}
+				g, found := groups[key]
+				if !found {
+					g = newGroup(key, gop.size)
+					groups[key] = g
+					if end = o.sendToFlow(ctx, g.GroupedObservable, out); end {
+						continue
+					}
+				}
+				if timer != nil {
+					g.last = clock.Now()
+					if !armed {
+						timer.Reset(o.timespan)
+						armed = true
+					}
+				}
+				if !g.push(ctx, x) {
+					end = true
+				}
+			case <-expires:
+				// complete idle groups, and wait for the earliest one of others
+				now := clock.Now()
+				var next time.Duration
+				for key, g := range groups {
+					if idle := now.Sub(g.last); idle >= o.timespan {
+						close(g.ch)
+						delete(groups, key)
+					} else if wait := o.timespan - idle; next == 0 || wait < next {
+						next = wait
+					}
+				}
+				armed = next > 0
+				if armed {
+					timer.Reset(next)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package rxgo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// emits "key:items" of each group
+func describeGroups(o *Observable) *Observable {
+	return o.FlatMap(func(g *GroupedObservable) *Observable {
+		return g.ToSlice().Map(func(items []interface{}) string {
+			return fmt.Sprint(g.Key(), ":", items)
+		})
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	res, completed := collectAll(describeGroups(Range(0, 10).GroupBy(func(x int) int {
+		return x % 3
+	})))
+
+	assert.ElementsMatch(t, []interface{}{"0:[0 3 6 9]", "1:[1 4 7]", "2:[2 5 8]"}, res, "GroupBy Test Error!")
+	assert.True(t, completed, "GroupBy Completed Error!")
+
+	ee := errors.New("Any")
+	res, _ = collectAll(describeGroups(Just("a", ee, "b", "aa").GroupBy(func(s string) int {
+		return len(s)
+	})))
+	assert.ElementsMatch(t, []interface{}{ee, "1:[a b]", "2:[aa]"}, res, "GroupBy error Test Error!")
+}
+
+func TestGroupByUncomparableKey(t *testing.T) {
+	res, completed := collectAll(describeGroups(Just(1, 2, 3).GroupBy(func(x int) interface{} {
+		if x == 2 {
+			return []int{x}
+		}
+		return x % 2
+	})))
+
+	assert.ElementsMatch(t, []interface{}{FlowableError{Err: ErrUncomparableKey, Elements: 2}, "1:[1 3]"}, res, "GroupBy uncomparable key Test Error!")
+	assert.True(t, completed, "GroupBy Completed Error!")
+}
+
+func TestGroupByMapOrdered(t *testing.T) {
+	// only FlatMap serves groups at the same time, other operators keep the order of groups
+	var p concurrencyProbe
+	res, completed := collectAll(Range(0, 30).GroupBy(func(x int) int {
+		return x % 5
+	}).Map(func(g *GroupedObservable) interface{} {
+		p.enter()
+		defer p.leave()
+		time.Sleep(time.Millisecond)
+		return g.Key()
+	}))
+
+	assert.Equal(t, []interface{}{0, 1, 2, 3, 4}, res, "GroupBy Map Test Error!")
+	assert.True(t, completed, "GroupBy Map Completed Error!")
+	assert.Equal(t, int32(1), p.max, "GroupBy Map is concurrent!")
+}
+
+func TestGroupByBounded(t *testing.T) {
+	// groups are served at the same time, so small buffers do not block each other
+	sum := 0
+	Range(0, 1000).GroupByWith(func(x int) int {
+		return x % 10
+	}, 1, 0).FlatMap(func(g *GroupedObservable) *Observable {
+		return g.Count()
+	}).Subscribe(func(n int) {
+		sum += n
+	})
+	assert.Equal(t, 1000, sum, "GroupByWith bounded Test Error!")
+
+	// items of a group are dropped after its subscriber left
+	res, _ := collectAll(Range(0, 100).GroupByWith(func(x int) int {
+		return x % 2
+	}, 1, 0).FlatMap(func(g *GroupedObservable) *Observable {
+		return g.First()
+	}))
+	assert.ElementsMatch(t, []interface{}{0, 1}, res, "GroupByWith unsubscribed Test Error!")
+}
+
+func TestGroupByExpiry(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  "a1",
+		5 * time.Millisecond:  "a2",
+		8 * time.Millisecond:  "b1",
+		12 * time.Millisecond: "a3",
+		30 * time.Millisecond: "a4",
+	}, 40*time.Millisecond)
+	s := v.subscribe(describeGroups(source.GroupByWith(func(s string) byte {
+		return s[0]
+	}, 0, 10*time.Millisecond)), ts)
+
+	// group a expires at 22ms, and group b at 18ms
+	ts.AdvanceBy(50 * time.Millisecond)
+	s.Wait()
+	assert.ElementsMatch(t, []interface{}{"97:[a1 a2 a3]", "98:[b1]", "97:[a4]"}, v.items(), "GroupByWith expiry Test Error!")
+}
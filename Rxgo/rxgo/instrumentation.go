@@ -0,0 +1,329 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Instrumentation receives events of Observables to export metrics and traces.
+// It is set in the subscriber context by WithInstrumentation, and its methods are called concurrently.
+// Observables are reported by the pointer, and Name of the Observable tells what operator it is
+type Instrumentation interface {
+	// Subscribed is called before a subscription connects the Observables ending with o.
+	// The returned context, derived from ctx, is used by the subscription
+	Subscribed(ctx context.Context, o *Observable) context.Context
+	// Unsubscribed is called after all goroutines of the subscription exited. err is the error terminated the stream,
+	// context.Canceled if it is cancelled, or nil if it is completed
+	Unsubscribed(ctx context.Context, o *Observable, err error)
+	// ItemSent is called when o sent an item to its outflow after waiting for the downstream in wait.
+	// queued is the number of items in the outflow then
+	ItemSent(ctx context.Context, o *Observable, wait time.Duration, queued int)
+	// ErrorSent is called when o sent an error to its outflow
+	ErrorSent(ctx context.Context, o *Observable, e error)
+	// FlowClosed is called when o closed its outflow
+	FlowClosed(ctx context.Context, o *Observable)
+	// GoroutineStarted and GoroutineExited are called for goroutines of Observables
+	GoroutineStarted(ctx context.Context)
+	GoroutineExited(ctx context.Context)
+}
+
+type instrumentationKey struct{}
+
+// WithInstrumentation returns a copy of ctx with the Instrumentation. Observables subscribed with the context report to it
+func WithInstrumentation(ctx context.Context, inst Instrumentation) context.Context {
+	return context.WithValue(ctx, instrumentationKey{}, inst)
+}
+
+// InstrumentationOf returns the Instrumentation of the subscriber context, or nil
+func InstrumentationOf(ctx context.Context) Instrumentation {
+	inst, _ := ctx.Value(instrumentationKey{}).(Instrumentation)
+	return inst
+}
+
+// NopInstrumentation ignores all events. Embed it to implement a part of Instrumentation
+type NopInstrumentation struct{}
+
+var _ Instrumentation = NopInstrumentation{}
+
+func (NopInstrumentation) Subscribed(ctx context.Context, o *Observable) context.Context {
+	return ctx
+}
+
+func (NopInstrumentation) Unsubscribed(ctx context.Context, o *Observable, err error) {}
+
+func (NopInstrumentation) ItemSent(ctx context.Context, o *Observable, wait time.Duration, queued int) {
+}
+
+func (NopInstrumentation) ErrorSent(ctx context.Context, o *Observable, e error) {}
+
+func (NopInstrumentation) FlowClosed(ctx context.Context, o *Observable) {}
+
+func (NopInstrumentation) GoroutineStarted(ctx context.Context) {}
+
+func (NopInstrumentation) GoroutineExited(ctx context.Context) {}
+
+// Instruments reports events to all of insts in order
+func Instruments(insts ...Instrumentation) Instrumentation {
+	return multiInstrumentation(insts)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (m multiInstrumentation) Subscribed(ctx context.Context, o *Observable) context.Context {
+	for _, inst := range m {
+		ctx = inst.Subscribed(ctx, o)
+	}
+	return ctx
+}
+
+func (m multiInstrumentation) Unsubscribed(ctx context.Context, o *Observable, err error) {
+	for _, inst := range m {
+		inst.Unsubscribed(ctx, o, err)
+	}
+}
+
+func (m multiInstrumentation) ItemSent(ctx context.Context, o *Observable, wait time.Duration, queued int) {
+	for _, inst := range m {
+		inst.ItemSent(ctx, o, wait, queued)
+	}
+}
+
+func (m multiInstrumentation) ErrorSent(ctx context.Context, o *Observable, e error) {
+	for _, inst := range m {
+		inst.ErrorSent(ctx, o, e)
+	}
+}
+
+func (m multiInstrumentation) FlowClosed(ctx context.Context, o *Observable) {
+	for _, inst := range m {
+		inst.FlowClosed(ctx, o)
+	}
+}
+
+func (m multiInstrumentation) GoroutineStarted(ctx context.Context) {
+	for _, inst := range m {
+		inst.GoroutineStarted(ctx)
+	}
+}
+
+func (m multiInstrumentation) GoroutineExited(ctx context.Context) {
+	for _, inst := range m {
+		inst.GoroutineExited(ctx)
+	}
+}
+
+// SpanStarter starts a span named name as a child of the span in ctx, like Tracer.Start of OpenTelemetry.
+// It returns the context with the span, and a function ending the span with the error of the subscription
+type SpanStarter func(ctx context.Context, name string) (context.Context, func(err error))
+
+// SubscriptionSpans creates an Instrumentation starting a span for each subscription, which is named
+// "rxgo.subscribe " and the Name of the last Observable. It is ended when the subscription ended.
+// With OpenTelemetry, start may be:
+//
+//	func(ctx context.Context, name string) (context.Context, func(error)) {
+//		ctx, span := tracer.Start(ctx, name)
+//		return ctx, func(err error) {
+//			if err != nil {
+//				span.RecordError(err)
+//				span.SetStatus(codes.Error, err.Error())
+//			}
+//			span.End()
+//		}
+//	}
+func SubscriptionSpans(start SpanStarter) Instrumentation {
+	return spanInstrumentation{start: start}
+}
+
+type spanInstrumentation struct {
+	NopInstrumentation
+	start SpanStarter
+}
+
+type spanEndKey struct{}
+
+func (si spanInstrumentation) Subscribed(ctx context.Context, o *Observable) context.Context {
+	ctx, end := si.start(ctx, "rxgo.subscribe "+o.Name)
+	return context.WithValue(ctx, spanEndKey{}, end)
+}
+
+func (si spanInstrumentation) Unsubscribed(ctx context.Context, o *Observable, err error) {
+	if end, ok := ctx.Value(spanEndKey{}).(func(err error)); ok {
+		end(err)
+	}
+}
+
+// PrometheusExporter is an Instrumentation counting events of each Observable node of chains,
+// and writes the metrics in the Prometheus text format. It is also the http.Handler of the metrics.
+// A node is labeled by a unique id and its Name, so that operators of the same Name do not share metrics,
+// and all subscriptions of a chain count to its nodes.
+// Items into an Observable are counted by the items sent by its predecessor
+type PrometheusExporter struct {
+	mu            sync.Mutex
+	operators     map[uint64]*operatorMetrics
+	goroutines    int64
+	subscriptions int64 // active subscriptions
+	subscribed    int64
+	failed        int64
+}
+
+type operatorMetrics struct {
+	name                    string
+	in, out, errors, closed uint64
+	wait                    time.Duration // sum of waiting time of items sent
+	queued                  int           // items in the outflow after the last item sent
+}
+
+var _ Instrumentation = &PrometheusExporter{}
+var _ http.Handler = &PrometheusExporter{}
+
+// NewPrometheusExporter creates a PrometheusExporter without metrics
+func NewPrometheusExporter() *PrometheusExporter {
+	return &PrometheusExporter{operators: make(map[uint64]*operatorMetrics)}
+}
+
+// must hold p.mu
+func (p *PrometheusExporter) operator(o *Observable) *operatorMetrics {
+	m, ok := p.operators[o.id]
+	if !ok {
+		m = &operatorMetrics{name: o.Name}
+		p.operators[o.id] = m
+	}
+	return m
+}
+
+func (p *PrometheusExporter) Subscribed(ctx context.Context, o *Observable) context.Context {
+	p.mu.Lock()
+	p.subscriptions++
+	p.subscribed++
+	p.mu.Unlock()
+	return ctx
+}
+
+func (p *PrometheusExporter) Unsubscribed(ctx context.Context, o *Observable, err error) {
+	p.mu.Lock()
+	p.subscriptions--
+	if err != nil && err != context.Canceled {
+		p.failed++
+	}
+	p.mu.Unlock()
+}
+
+func (p *PrometheusExporter) ItemSent(ctx context.Context, o *Observable, wait time.Duration, queued int) {
+	p.mu.Lock()
+	m := p.operator(o)
+	m.out++
+	m.wait += wait
+	m.queued = queued
+	if o.next != nil {
+		p.operator(o.next).in++
+	}
+	p.mu.Unlock()
+}
+
+func (p *PrometheusExporter) ErrorSent(ctx context.Context, o *Observable, e error) {
+	p.mu.Lock()
+	p.operator(o).errors++
+	p.mu.Unlock()
+}
+
+func (p *PrometheusExporter) FlowClosed(ctx context.Context, o *Observable) {
+	p.mu.Lock()
+	p.operator(o).closed++
+	p.mu.Unlock()
+}
+
+func (p *PrometheusExporter) GoroutineStarted(ctx context.Context) {
+	p.mu.Lock()
+	p.goroutines++
+	p.mu.Unlock()
+}
+
+func (p *PrometheusExporter) GoroutineExited(ctx context.Context) {
+	p.mu.Lock()
+	p.goroutines--
+	p.mu.Unlock()
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (p *PrometheusExporter) WriteTo(w io.Writer) (n int64, err error) {
+	p.mu.Lock()
+	ids := make([]uint64, 0, len(p.operators))
+	ops := make(map[uint64]operatorMetrics, len(p.operators))
+	labels := make(map[uint64]string, len(p.operators))
+	for id, m := range p.operators {
+		ids = append(ids, id)
+		ops[id] = *m
+		labels[id] = fmt.Sprintf("operator=\"%s\",node=\"%d\"", escapeLabel(m.name), id)
+	}
+	goroutines, subscriptions, subscribed, failed := p.goroutines, p.subscriptions, p.subscribed, p.failed
+	p.mu.Unlock()
+	// nodes are created from the first one of a chain
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	bw := bufio.NewWriter(w)
+	cw := &countWriter{w: bw}
+	family := func(name, kind, help string, value func(m operatorMetrics) interface{}) {
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, id := range ids {
+			fmt.Fprintf(cw, "%s{%s} %v\n", name, labels[id], value(ops[id]))
+		}
+	}
+	family("rxgo_items_in_total", "counter", "Items received by the operator.", func(m operatorMetrics) interface{} { return m.in })
+	family("rxgo_items_out_total", "counter", "Items sent by the operator.", func(m operatorMetrics) interface{} { return m.out })
+	family("rxgo_errors_total", "counter", "Errors sent by the operator.", func(m operatorMetrics) interface{} { return m.errors })
+	family("rxgo_flows_closed_total", "counter", "Outflows closed by the operator.", func(m operatorMetrics) interface{} { return m.closed })
+	family("rxgo_outflow_queued", "gauge", "Items in the outflow of the operator after its last item sent.", func(m operatorMetrics) interface{} { return m.queued })
+	fmt.Fprintf(cw, "# HELP rxgo_send_wait_seconds Time the operator waited for the downstream to send an item.\n# TYPE rxgo_send_wait_seconds summary\n")
+	for _, id := range ids {
+		fmt.Fprintf(cw, "rxgo_send_wait_seconds_sum{%s} %v\n", labels[id], ops[id].wait.Seconds())
+		fmt.Fprintf(cw, "rxgo_send_wait_seconds_count{%s} %v\n", labels[id], ops[id].out)
+	}
+	fmt.Fprintf(cw, "# HELP rxgo_goroutines Goroutines of Observables.\n# TYPE rxgo_goroutines gauge\nrxgo_goroutines %d\n", goroutines)
+	fmt.Fprintf(cw, "# HELP rxgo_subscriptions Active subscriptions.\n# TYPE rxgo_subscriptions gauge\nrxgo_subscriptions %d\n", subscriptions)
+	fmt.Fprintf(cw, "# HELP rxgo_subscriptions_total Subscriptions started.\n# TYPE rxgo_subscriptions_total counter\nrxgo_subscriptions_total %d\n", subscribed)
+	fmt.Fprintf(cw, "# HELP rxgo_subscriptions_failed_total Subscriptions terminated by an error.\n# TYPE rxgo_subscriptions_failed_total counter\nrxgo_subscriptions_failed_total %d\n", failed)
+	if cw.err == nil {
+		cw.err = bw.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP writes the metrics, so that the exporter can be scraped by Prometheus
+func (p *PrometheusExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// count bytes written, and keep the first error
+type countWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) Write(b []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
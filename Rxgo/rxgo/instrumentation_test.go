@@ -0,0 +1,144 @@
+package rxgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subscribeWith(o *Observable, inst Instrumentation) {
+	o.Subscribe(ObserverMonitor{
+		Context: func() context.Context {
+			return WithInstrumentation(context.Background(), inst)
+		},
+	})
+}
+
+func TestPrometheusExporter(t *testing.T) {
+	ee := errors.New("Any")
+	p := NewPrometheusExporter()
+	just := Just(1, ee, 2, 3)
+	// both stages are named "map"
+	double := just.Map(func(x int) int {
+		return x * 2
+	})
+	inc := double.Map(func(x int) int {
+		return x + 1
+	})
+	filter := inc.Filter(func(x int) bool {
+		return x > 3
+	})
+	subscribeWith(filter, p)
+
+	var buf bytes.Buffer
+	n, err := p.WriteTo(&buf)
+	assert.NoError(t, err, "PrometheusExporter Test Error!")
+	assert.Equal(t, int64(buf.Len()), n, "PrometheusExporter Test Error!")
+	text := buf.String()
+	label := func(o *Observable) string {
+		return fmt.Sprintf(`{operator="%s",node="%d"}`, o.Name, o.id)
+	}
+	for _, line := range []string{
+		`# TYPE rxgo_items_out_total counter`,
+		`rxgo_items_out_total` + label(just) + ` 3`,
+		`rxgo_items_in_total` + label(double) + ` 3`,
+		`rxgo_items_out_total` + label(double) + ` 3`,
+		`rxgo_items_in_total` + label(inc) + ` 3`,
+		`rxgo_items_out_total` + label(inc) + ` 3`,
+		`rxgo_items_in_total` + label(filter) + ` 3`,
+		`rxgo_items_out_total` + label(filter) + ` 2`,
+		`rxgo_errors_total` + label(just) + ` 1`,
+		`rxgo_errors_total` + label(filter) + ` 1`,
+		`rxgo_flows_closed_total` + label(filter) + ` 1`,
+		`rxgo_send_wait_seconds_count` + label(double) + ` 3`,
+		`rxgo_goroutines 0`,
+		`rxgo_subscriptions 0`,
+		`rxgo_subscriptions_total 1`,
+		`rxgo_subscriptions_failed_total 0`,
+	} {
+		assert.Contains(t, text, line+"\n", "PrometheusExporter Test Error!")
+	}
+	// another subscription counts to the same nodes
+	subscribeWith(filter, p)
+	buf.Reset()
+	p.WriteTo(&buf)
+	assert.Contains(t, buf.String(), `rxgo_items_out_total`+label(filter)+" 4\n", "PrometheusExporter Test Error!")
+
+	// served as text
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"), "PrometheusExporter Test Error!")
+	assert.Equal(t, buf.String(), rec.Body.String(), "PrometheusExporter Test Error!")
+}
+
+// record spans started and ended
+type spanRecorder struct {
+	mu    sync.Mutex
+	names []string
+	errs  []error
+}
+
+func (r *spanRecorder) start(ctx context.Context, name string) (context.Context, func(err error)) {
+	r.mu.Lock()
+	r.names = append(r.names, name)
+	r.mu.Unlock()
+	return ctx, func(err error) {
+		r.mu.Lock()
+		r.errs = append(r.errs, err)
+		r.mu.Unlock()
+	}
+}
+
+func TestSubscriptionSpans(t *testing.T) {
+	ee := errors.New("Any")
+	r := &spanRecorder{}
+	p := NewPrometheusExporter()
+	inst := Instruments(SubscriptionSpans(r.start), p)
+
+	subscribeWith(Just(1, 2).Map(func(x int) int { return x }), inst)
+	subscribeWith(Just(1, ee, 3).SetErrorPolicy(TerminateOnError), inst)
+
+	var sent int64
+	s := countingSource(&sent).SubscribeAsync(ObserverMonitor{
+		Context: func() context.Context {
+			return WithInstrumentation(context.Background(), inst)
+		},
+	})
+	s.Dispose()
+	s.Wait()
+
+	assert.Equal(t, []string{"rxgo.subscribe map", "rxgo.subscribe Just", "rxgo.subscribe CustomSource"}, r.names, "SubscriptionSpans Test Error!")
+	assert.Equal(t, []error{nil, ee, context.Canceled}, r.errs, "SubscriptionSpans Test Error!")
+
+	var buf bytes.Buffer
+	p.WriteTo(&buf)
+	assert.Contains(t, buf.String(), "rxgo_subscriptions_total 3\n", "SubscriptionSpans Test Error!")
+	assert.Contains(t, buf.String(), "rxgo_subscriptions_failed_total 1\n", "SubscriptionSpans Test Error!")
+}
+
+// counts ItemSent of each operator
+type sentCounter struct {
+	NopInstrumentation
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+func (c *sentCounter) ItemSent(ctx context.Context, o *Observable, wait time.Duration, queued int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent[o.Name]++
+}
+
+func TestInstrumentationBackpressure(t *testing.T) {
+	// items sent at once and items sent from the buffer are both reported
+	c := &sentCounter{sent: make(map[string]int)}
+	subscribeWith(Range(0, 100).OnBackpressureBuffer(100, OverflowDropLatest).SetBufferLen(100), c)
+	assert.Equal(t, 100, c.sent["onBackpressureBuffer"], "Instrumentation backpressure Test Error!")
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package rxgo
+
+import (
+	"context"
+	"iter"
+)
+
+// Iterate returns an iterator subscribing the Observable, which yields items with nil errors and errors with nil items.
+// The subscription is cancelled when the loop breaks
+func (o *Observable) Iterate() iter.Seq2[interface{}, error] {
+	return func(yield func(interface{}, error) bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := o.ToChannel(ctx)
+		for it := range ch {
+			if !yield(it.Value, it.Err) {
+				// wait for the subscription ended
+				cancel()
+				for range ch {
+				}
+				return
+			}
+		}
+	}
+}
+
+// FromSeq creates an Observable that emits values of the iterator
+func FromSeq[T any](seq iter.Seq[T]) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for x := range seq {
+			if send(x) {
+				return
+			}
+		}
+	})
+	o.Name = "FromSeq"
+	return o
+}
+
+// FromIter creates an Observable that emits values of the iterator, and sends its non-nil errors to stream
+func FromIter[T any](seq iter.Seq2[T, error]) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for x, e := range seq {
+			var item interface{} = x
+			if e != nil {
+				item = e
+			}
+			if send(item) {
+				return
+			}
+		}
+	})
+	o.Name = "FromIter"
+	return o
+}
@@ -0,0 +1,60 @@
+//go:build go1.23
+
+package rxgo
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterate(t *testing.T) {
+	ee := errors.New("Any")
+	var items []interface{}
+	var errs []error
+	for x, e := range Just(1, ee, 3).Iterate() {
+		if e != nil {
+			errs = append(errs, e)
+			continue
+		}
+		items = append(items, x)
+	}
+	assert.Equal(t, []interface{}{1, 3}, items, "Iterate Test Error!")
+	assert.Equal(t, []error{ee}, errs, "Iterate Test Error!")
+
+	// break an endless Observable
+	var sent int64
+	items = nil
+	for x := range countingSource(&sent).Iterate() {
+		items = append(items, x)
+		if len(items) == 3 {
+			break
+		}
+	}
+	assert.Equal(t, []interface{}{0, 1, 2}, items, "Iterate break Test Error!")
+}
+
+func TestFromSeq(t *testing.T) {
+	res, completed := collectAll(FromSeq(slices.Values([]string{"a", "b"})))
+	assert.Equal(t, []interface{}{"a", "b"}, res, "FromSeq Test Error!")
+	assert.True(t, completed, "FromSeq Test Error!")
+
+	res, _ = collectAll(FromSeq(maps.Keys(map[int]bool{1: true})).Take(1))
+	assert.Equal(t, []interface{}{1}, res, "FromSeq Test Error!")
+}
+
+func TestFromIter(t *testing.T) {
+	ee := errors.New("Any")
+	seq := func(yield func(int, error) bool) {
+		_ = yield(1, nil) && yield(0, ee) && yield(2, nil)
+	}
+	res, _ := collectAll(FromIter(seq))
+	assert.Equal(t, []interface{}{1, ee, 2}, res, "FromIter Test Error!")
+
+	// round trip
+	res, _ = collectAll(FromIter(Just(1, ee, 3).Iterate()))
+	assert.Equal(t, []interface{}{1, ee, 3}, res, "FromIter Test Error!")
+}
@@ -8,8 +8,11 @@ package rxgo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +24,36 @@ const (
 	ThreadingComputing                    // each item served by one goroutine in a limited group
 )
 
+func (t ThreadModel) String() string {
+	switch t {
+	case ThreadingDefault:
+		return "default"
+	case ThreadingIO:
+		return "io"
+	case ThreadingComputing:
+		return "computing"
+	}
+	return fmt.Sprint("ThreadModel(", uint(t), ")")
+}
+
+// ErrorPolicy decides what an Observable does after it sends an error
+type ErrorPolicy uint
+
+const (
+	ContinueOnError  ErrorPolicy = iota // an error flows as an item, and the stream goes on
+	TerminateOnError                    // an error terminates the stream, like ReactiveX
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case ContinueOnError:
+		return "continue"
+	case TerminateOnError:
+		return "terminate"
+	}
+	return fmt.Sprint("ErrorPolicy(", uint(p), ")")
+}
+
 // Subscribe paeameter error
 var ErrFuncOnNext = errors.New("Subscribe paramteter needs func(x anytype) or Observer or ObserverWithContext")
 
@@ -33,6 +66,21 @@ var ErrEoFlow = errors.New("End of Flow!")
 // if user function throw SkipItem, the Observeable will skip current item
 var ErrSkipItem = errors.New("Skip item!")
 
+// blocking operators return ErrEmptyObservable if there is no item, and First, Last and Single send it
+var ErrEmptyObservable = errors.New("Empty Observable!")
+
+// Single sends it if there are more than one item
+var ErrNotSingle = errors.New("More than one item!")
+
+// ElementAt sends it if there are not enough items
+var ErrIndexOutOfRange = errors.New("Index out of range!")
+
+// Timeout sends it if no item arrives in time and there is no fallback
+var ErrTimeout = errors.New("Timeout!")
+
+// mathematical operators send it in a FlowableError if an item is not a number
+var ErrNotNumber = errors.New("Item is not a number!")
+
 // Error that can flow to subscriber or user function which processes error as an input
 type FlowableError struct {
 	Err      error
@@ -109,10 +157,10 @@ type streamOperator interface {
 	op(ctx context.Context, o *Observable)
 }
 
-//emit something
+// emit something
 type sourceFunc func(ctx context.Context, send func(x interface{}) (endSignal bool))
 
-//transform any item
+// transform any item
 type transformFunc func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool))
 
 // default buffer of channels
@@ -120,55 +168,170 @@ var BufferLen uint = 128
 
 // An Observable is a 'collection of items that arrive over time'. Observables can be used to model asynchronous events.
 // Observables can also be chained by operators to transformed, combined those items
-// The Observable's operators, by default, run with a channel size of 128 elements except that the source (first) observable has no buffer
+// The Observable's operators, by default, run with a channel size of 128 elements except that the source (first) observable has no buffer.
+// An Observable is never changed by operators: each operator returns a new Observable linked to its parent,
+// so that one chain can be shared by many derived chains and subscriptions
 type Observable struct {
 	Name string
-	mu   sync.Mutex // lock all when creating subscriber
 	//
 	flip     interface{} // transformation function
 	outflow  chan interface{}
 	operator streamOperator
-	// chain of Observables
-	root *Observable
+	// chain of Observables. pred is the upstream Observable, nil for the first one.
+	// next is the downstream Observable of a connected copy, nil for chains built by operators
 	next *Observable
 	pred *Observable
 	// control model
-	threading ThreadModel //threading model. if this is root, it represents obseverOn model
-	buf_len   uint
+	threading   ThreadModel //threading model. if this is the first one and no ObserveOn, it represents obseverOn model
+	buf_len     uint
+	concurrency uint // max items processed at the same time, 0 means no limit
+	ordered     bool // re-sequence results of concurrent items in their input order
+	// what to do after sending an error, inherited by the following Observables
+	error_policy ErrorPolicy
+	// set by ObserveOn and ObserveOnScheduler, the one nearest to the subscriber is used
+	observe_set   bool
+	observe_on    ThreadModel
+	observe_sched Scheduler // Scheduler delivering items to observer, it overrides observe_on
 	// utility vars
 	debug             Observer
-	flip_sup_ctx      bool //indicate that flip function use context as first paramter
-	flip_accept_error bool // indicate that flip function input's data is type interface{} or error
-	computation bool //调度器
-	timespan time.Duration  //时间间隔
+	flip_sup_ctx      bool          //indicate that flip function use context as first paramter
+	flip_accept_error bool          // indicate that flip function input's data is type interface{} or error
+	timespan          time.Duration //时间间隔
+	// upstream Observables of a combining node, connected when this node is connected
+	sources []*Observable
+	// unique id of the node, kept by its copies of configuration methods and connections
+	id uint64
+	// Instrumentation of the subscriber context, looked up when the node is connected
+	inst Instrumentation
 }
 
+// last id of Observables created
+var observableID uint64
+
 func newObservable() *Observable {
-	return &Observable{}
+	return &Observable{id: atomic.AddUint64(&observableID, 1)}
+}
+
+// copy the Observable, configuration methods change the copy and leave the Observable to other chains
+func (o *Observable) clone() *Observable {
+	c := *o
+	return &c
 }
 
-// connect all Observable form the first one.
-func (o *Observable) connect(ctx context.Context) {
-	for po := o.root; po != nil; po = po.next {
+// connect all Observable form the first one to o. The Observables are copied for the connection,
+// so that each subscription gets its own channels and the chain is never changed. It returns the copy of o.
+// Each Observable gets a context derived from the one of its successor, so that it can cancel its upstream
+func (o *Observable) connect(ctx context.Context) *Observable {
+	var chain []*Observable
+	for po := o; po != nil; po = po.pred {
+		chain = append(chain, po.clone())
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	for i := 1; i < len(chain); i++ {
+		chain[i].pred = chain[i-1]
+		chain[i-1].next = chain[i]
+	}
+
+	ctxs := make([]context.Context, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		var cancel context.CancelFunc
+		upctx := ctx
+		if i > 0 {
+			upctx, cancel = context.WithCancel(ctx)
+		}
+		// a root never cancels the Observables of outer chains
+		ctxs[i] = context.WithValue(ctx, upstreamKey{}, cancel)
+		ctx = upctx
+	}
+	for i, po := range chain {
 		po.outflow = make(chan interface{}, po.buf_len)
-		po.operator.op(ctx, po)
+		po.inst = InstrumentationOf(ctxs[i])
+		po.operator.op(ctxs[i], po)
 		//fmt.Println("conneted", po.name, po.outflow)
 	}
+	return chain[len(chain)-1]
 }
 
+type upstreamKey struct{}
+
+// cancelUpstream cancels the Observables before the one connected with ctx, and they close their outflows then.
+// Operators that end before their upstream, such as Take, use it to release the upstream
+func cancelUpstream(ctx context.Context) {
+	if cancel, _ := ctx.Value(upstreamKey{}).(context.CancelFunc); cancel != nil {
+		cancel()
+	}
+}
+
+// connect the chain ending with o and return outflow of o
+func (o *Observable) connectTail(ctx context.Context) chan interface{} {
+	return o.connect(ctx).outflow
+}
+
+// SubscribeOn returns a copy of the Observable, which serves items by the ThreadModel.
+// Stateful filtering operators, such as Distinct, TakeWhile and Debounce, ignore it,
+// and their functions always run one by one on the goroutine of the stream
 func (o *Observable) SubscribeOn(t ThreadModel) *Observable {
+	o = o.clone()
 	o.threading = t
+	o.ordered = false
+	return o
+}
+
+// SubscribeOnOrdered returns a copy of the Observable, which processes up to n items at the same time,
+// each item served by one goroutine, and emits their results in the order of input items. 0 means runtime.NumCPU()
+func (o *Observable) SubscribeOnOrdered(n uint) *Observable {
+	if n == 0 {
+		n = uint(runtime.NumCPU())
+	}
+	o = o.clone()
+	o.threading = ThreadingIO
+	o.concurrency = n
+	o.ordered = true
 	return o
 }
 
+// ObserveOn decides where the observer receives items: ThreadingDefault on the goroutine calling Subscribe,
+// ThreadingIO on a dedicated goroutine, and ThreadingComputing on the shared computing group.
+// It returns a copy of the Observable, and the ObserveOn nearest to the subscriber is used
 func (o *Observable) ObserveOn(t ThreadModel) *Observable {
-	po := o.root
-	po.threading = t
+	o = o.clone()
+	o.observe_set = true
+	o.observe_on = t
+	o.observe_sched = nil
+	return o
+}
+
+// ObserveOnScheduler makes the observer receive items on the Scheduler, such as a caller-supplied executor.
+// Items are still delivered one by one. It returns a copy of the Observable like ObserveOn
+func (o *Observable) ObserveOnScheduler(s Scheduler) *Observable {
+	o = o.clone()
+	o.observe_set = true
+	o.observe_sched = s
 	return o
 }
 
+// Subscribe connects the Observables and blocks until the last one completed
 func (o *Observable) Subscribe(ob interface{}) {
-	o.mu.Lock()
+	s := o.subscribe(ob)
+	if s.dedicated {
+		go s.run()
+		s.Wait()
+		return
+	}
+	s.run()
+}
+
+// SubscribeAsync connects the Observables and returns at once.
+// Items are delivered on a dedicated goroutine or the Scheduler set by ObserveOn
+func (o *Observable) SubscribeAsync(ob interface{}) *Subscription {
+	s := o.subscribe(ob)
+	go s.run()
+	return s
+}
+
+func (o *Observable) subscribe(ob interface{}) *Subscription {
 	fv, ft := reflect.ValueOf(ob), reflect.TypeOf(ob)
 
 	var observer Observer
@@ -195,56 +358,80 @@ func (o *Observable) Subscribe(ob interface{}) {
 		ctx = oc.GetObserverContext()
 		//fmt.Println("ctx geted!", ctx)
 	}
+	ctx, cancel := context.WithCancel(ctx)
+	flows := &sync.WaitGroup{}
+	ctx = context.WithValue(ctx, flowGroupKey{}, flows)
+
+	inst := InstrumentationOf(ctx)
+	if inst != nil {
+		ctx = inst.Subscribed(ctx, o)
+	}
 
 	//fmt.Println("begin conneted", o.name)
-	o.connect(ctx)
+	po := o.connect(ctx)
 	if ctxok {
 		oc.OnConnected()
 	}
 
-	//get the last ob servable
-	po := o
-	for ; po.next != nil; po = po.next {
+	s := &Subscription{
+		ctx:                ctx,
+		cancel:             cancel,
+		done:               make(chan struct{}),
+		flows:              flows,
+		in:                 po.outflow,
+		observer:           observer,
+		fv:                 fv,
+		terminate_on_error: po.error_policy == TerminateOnError,
+		last:               po,
+		inst:               inst,
 	}
+	switch observe, sched := o.observeModel(); {
+	case sched != nil:
+		s.sched = sched
+	case observe == ThreadingIO:
+		s.dedicated = true
+	case observe == ThreadingComputing:
+		s.sched = ComputingScheduler()
+	}
+	return s
+}
 
-	in := po.outflow
-	o.mu.Unlock()
-
-	for x := range in {
-		if observer != nil {
-			if e, ok := x.(error); ok {
-				observer.OnError(e)
-
-			} else {
-				observer.OnNext(x)
-			}
-		} else {
-			if _, ok := x.(error); ok {
-				// skip error
-			} else {
-				params := []reflect.Value{reflect.ValueOf(x)}
-				fv.Call(params)
-			}
-		}
+// where the observer receives items: the nearest ObserveOn, or the threading model of the first Observable
+func (o *Observable) observeModel() (ThreadModel, Scheduler) {
+	po := o
+	for ; !po.observe_set && po.pred != nil; po = po.pred {
 	}
-	if observer != nil {
-		observer.OnCompleted()
+	if po.observe_set {
+		return po.observe_on, po.observe_sched
 	}
+	return po.threading, nil
 }
 
+// SetErrorPolicy returns a copy of the Observable with the ErrorPolicy, which is inherited by the Observables chained after it.
+// With TerminateOnError on the last Observable, the observer receives no more items or OnCompleted after OnError
+func (o *Observable) SetErrorPolicy(p ErrorPolicy) *Observable {
+	o = o.clone()
+	o.error_policy = p
+	return o
+}
+
+// SetBufferLen returns a copy of the Observable of which the outflow buffers length items
 func (o *Observable) SetBufferLen(length uint) *Observable {
+	o = o.clone()
 	o.buf_len = length
 	return o
 }
 
-// set a observer to monite items in data stream
+// set a observer to monite items in data stream, it returns a copy of the Observable
 func (o *Observable) SetMonitor(observer Observer) *Observable {
+	o = o.clone()
 	o.debug = observer
 	return o
 }
 
-// set a innerMonitor for debug
+// set a innerMonitor for debug, it returns a copy of the Observable
 func (o *Observable) Debug(debug bool) *Observable {
+	o = o.clone()
 	if debug && o.debug == nil {
 		o.debug = InnerObserver{o.Name + " debug "}
 	}
@@ -256,29 +443,63 @@ func (o *Observable) Debug(debug bool) *Observable {
 
 func (o *Observable) sendToFlow(ctx context.Context, item interface{}, out chan interface{}) (end bool) {
 	//fmt.Println("send chan ", o.name, item, out)
+	var start time.Time
+	if o.inst != nil {
+		start = time.Now()
+	}
 	select {
 	case out <- item:
-		if e, ok := item.(error); ok {
-			if o.debug != nil {
-				o.debug.OnError(e)
-			}
-		} else {
-			if o.debug != nil {
-				o.debug.OnNext(item)
-			}
-		}
 	case <-ctx.Done():
-		end = true
+		return true
+	default:
+		resume := yieldSlot(ctx, out)
+		select {
+		case out <- item:
+		case <-ctx.Done():
+			end = true
+		}
+		resume()
+		if end {
+			return
+		}
 	}
-	return
+	var wait time.Duration
+	if o.inst != nil {
+		wait = time.Since(start)
+	}
+	return o.sent(ctx, item, wait, out)
 }
 
-func (o *Observable) closeFlow(out chan interface{}) *Observable {
+// report the item sent to out after wait to the debug observer and the Instrumentation.
+// It returns true if the stream ends by the error policy
+func (o *Observable) sent(ctx context.Context, item interface{}, wait time.Duration, out chan interface{}) (end bool) {
+	if e, ok := item.(error); ok {
+		if o.debug != nil {
+			o.debug.OnError(e)
+		}
+		if o.inst != nil {
+			o.inst.ErrorSent(ctx, o, e)
+		}
+		return o.error_policy == TerminateOnError
+	}
+	if o.debug != nil {
+		o.debug.OnNext(item)
+	}
+	if o.inst != nil {
+		o.inst.ItemSent(ctx, o, wait, len(out))
+	}
+	return false
+}
+
+func (o *Observable) closeFlow(ctx context.Context, out chan interface{}) *Observable {
 	// maybe need waiting for parent observable closed
 	//fmt.Println("close chan ", o.name, out)
 	close(out)
 	if o.debug != nil {
 		o.debug.OnCompleted()
 	}
+	if o.inst != nil {
+		o.inst.FlowClosed(ctx, o)
+	}
 	return o
 }
@@ -1,5 +1,13 @@
 package rxgo
 
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
 // import (
 // 	"fmt"
 // 	"testing"
@@ -62,3 +70,66 @@ package rxgo
 // 	flow.Subscribe(observer{"test flatMap again"})
 // 	time.Sleep(time.Microsecond * 1000)
 // }
+
+func TestBranchingChains(t *testing.T) {
+	base := Just(1, 2, 3)
+	a := base.Map(func(x int) int {
+		return x * 10
+	})
+	b := base.Filter(func(x int) bool {
+		return x%2 == 1
+	})
+
+	res, _ := collectAll(a)
+	assert.Equal(t, []interface{}{10, 20, 30}, res, "Branching Test Error!")
+	res, _ = collectAll(b)
+	assert.Equal(t, []interface{}{1, 3}, res, "Branching Test Error!")
+	res, _ = collectAll(base)
+	assert.Equal(t, []interface{}{1, 2, 3}, res, "Branching Test Error!")
+
+	// both branches in one chain
+	res, _ = collectAll(Concat(a, b))
+	assert.Equal(t, []interface{}{10, 20, 30, 1, 3}, res, "Branching Test Error!")
+}
+
+func TestConcurrentSubscriptions(t *testing.T) {
+	o := Range(0, 100).Map(func(x int) int {
+		return x + 1
+	}).Sum()
+
+	var wg sync.WaitGroup
+	sums := make([]interface{}, 10)
+	for i := range sums {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, _ := collectAll(o)
+			sums[i] = res[0]
+		}(i)
+	}
+	wg.Wait()
+	for _, sum := range sums {
+		assert.Equal(t, 5050, sum, "ConcurrentSubscriptions Test Error!")
+	}
+}
+
+func TestConfigurationCopies(t *testing.T) {
+	ee := errors.New("Any")
+	base := Just(1, ee, 3)
+	terminated := base.SetErrorPolicy(TerminateOnError)
+
+	res, _ := collectAll(terminated)
+	assert.Equal(t, []interface{}{1, ee}, res, "SetErrorPolicy Test Error!")
+	res, _ = collectAll(base)
+	assert.Equal(t, []interface{}{1, ee, 3}, res, "SetErrorPolicy changes the parent!")
+	assert.Equal(t, ThreadingIO, base.Map(func(x int) int { return x }).SubscribeOn(ThreadingIO).threading, "SubscribeOn Test Error!")
+	assert.Equal(t, ThreadingDefault, base.threading, "SubscribeOn changes the parent!")
+
+	// a chain derived before SetConcurrency keeps its limit
+	mapped := base.Map(func(x int) int { return x }).SubscribeOn(ThreadingIO)
+	derived := mapped.SetConcurrency(1).Filter(func(x int) bool { return true })
+	limited := mapped.SetConcurrency(4)
+	assert.Equal(t, uint(4), limited.concurrency, "SetConcurrency Test Error!")
+	assert.Equal(t, uint(0), mapped.concurrency, "SetConcurrency changes the parent!")
+	assert.Equal(t, uint(1), derived.pred.concurrency, "SetConcurrency changes derived chains!")
+}
@@ -0,0 +1,383 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rxgotest tests Observables with marble diagrams on virtual time.
+//
+// A marble diagram is a string where each character is a frame of time:
+//
+//	a      an item, which is values["a"] or the string "a" if it is not in values
+//	-      nothing happens in the frame
+//	(ab)   items in the same frame, the group takes one frame
+//	#      an error. The Observable completes after it if it is the last event, like rxgo.Throw
+//	|      completion
+//	^      the subscription point of a hot Observable, which is frame 0
+//
+// Spaces are ignored, so that diagrams can be aligned. For example:
+//
+//	h := rxgotest.New(t)
+//	src := h.Cold("-a-b-c-|", nil)
+//	h.Expect(src.Delay(2*h.Frame), "---a-b-c|", nil)
+package rxgotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitee.com/li-jia666/rxgo"
+)
+
+// ErrMarble is the error of '#' if the harness has no Error
+var ErrMarble = errors.New("marble error")
+
+// Event is a Notification at a frame of a marble diagram
+type Event struct {
+	Frame int
+	rxgo.Notification
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case rxgo.OnNextNotification:
+		return fmt.Sprintf("%d: OnNext(%#v)", e.Frame, e.Value)
+	case rxgo.OnErrorNotification:
+		return fmt.Sprintf("%d: OnError(%v)", e.Frame, e.Err)
+	}
+	return fmt.Sprintf("%d: OnCompleted()", e.Frame)
+}
+
+// Parse parses the marble diagram into events, with values of items and the error of '#'.
+// Frames before '^' are negative. frames is the number of frames after '^', or of the diagram without '^'
+func Parse(marble string, values map[string]interface{}, err error) (events []Event, frames int, e error) {
+	frame, zero := 0, 0
+	group, subscribed, completed := false, false, false
+	for i, c := range marble {
+		if completed && c != '-' && c != ' ' && (c != ')' || !group) {
+			return nil, 0, fmt.Errorf("rxgotest: %q at %d after completion", c, i)
+		}
+		switch c {
+		case ' ':
+			continue
+		case '-':
+			if group {
+				return nil, 0, fmt.Errorf("rxgotest: '-' at %d in a group", i)
+			}
+		case '^':
+			if group || subscribed {
+				return nil, 0, fmt.Errorf("rxgotest: unexpected '^' at %d", i)
+			}
+			subscribed, zero = true, frame
+		case '(':
+			if group {
+				return nil, 0, fmt.Errorf("rxgotest: nested group at %d", i)
+			}
+			group = true
+			continue
+		case ')':
+			if !group {
+				return nil, 0, fmt.Errorf("rxgotest: unexpected ')' at %d", i)
+			}
+			group = false
+		case '|':
+			events = append(events, Event{frame, rxgo.Notification{Kind: rxgo.OnCompletedNotification}})
+			completed = true
+		case '#':
+			events = append(events, Event{frame, rxgo.Notification{Kind: rxgo.OnErrorNotification, Err: err}})
+		default:
+			x, ok := values[string(c)]
+			if !ok {
+				x = string(c)
+			}
+			events = append(events, Event{frame, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: x}})
+		}
+		if !group {
+			frame++
+		}
+	}
+	if group {
+		return nil, 0, errors.New("rxgotest: unclosed group")
+	}
+	if n := len(events); n > 0 && events[n-1].Kind == rxgo.OnErrorNotification {
+		events = append(events, Event{events[n-1].Frame, rxgo.Notification{Kind: rxgo.OnCompletedNotification}})
+	}
+	for i := range events {
+		events[i].Frame -= zero
+	}
+	return events, frame - zero, nil
+}
+
+// Format renders events as a marble diagram from frame 0. An item is rendered as its key in values,
+// or as itself if it is printed in one character, or in braces such as {10} otherwise.
+// The completion right after the last error is not rendered, as Parse adds it
+func Format(events []Event, values map[string]interface{}) string {
+	events = append([]Event(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Frame < events[j].Frame
+	})
+	if n := len(events); n > 1 && events[n-1].Kind == rxgo.OnCompletedNotification &&
+		events[n-2].Kind == rxgo.OnErrorNotification && events[n-2].Frame == events[n-1].Frame {
+		events = events[:n-1]
+	}
+	var sb strings.Builder
+	frame := 0
+	for i := 0; i < len(events); {
+		j := i
+		for j < len(events) && events[j].Frame == events[i].Frame {
+			j++
+		}
+		for ; frame < events[i].Frame; frame++ {
+			sb.WriteByte('-')
+		}
+		if j-i > 1 {
+			sb.WriteByte('(')
+		}
+		for _, e := range events[i:j] {
+			sb.WriteString(formatEvent(e, values))
+		}
+		if j-i > 1 {
+			sb.WriteByte(')')
+		}
+		frame++
+		i = j
+	}
+	return sb.String()
+}
+
+func formatEvent(e Event, values map[string]interface{}) string {
+	switch e.Kind {
+	case rxgo.OnErrorNotification:
+		return "#"
+	case rxgo.OnCompletedNotification:
+		return "|"
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if reflect.DeepEqual(values[k], e.Value) {
+			return k
+		}
+	}
+	if s := fmt.Sprint(e.Value); len([]rune(s)) == 1 && !strings.ContainsAny(s, "-^()|# ") {
+		return s
+	}
+	return fmt.Sprint("{", e.Value, "}")
+}
+
+// T is the part of testing.TB used by the Harness
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Harness creates test Observables from marble diagrams, and runs Observables on its TestScheduler.
+// Frame 0 is the time the Harness created
+type Harness struct {
+	Scheduler *rxgo.TestScheduler
+	Frame     time.Duration // virtual time of a frame, 10ms by default
+	Error     error         // error of '#', ErrMarble by default
+
+	t     T
+	start time.Time
+}
+
+// New creates a Harness reporting failures to t
+func New(t T) *Harness {
+	s := rxgo.NewTestScheduler()
+	return &Harness{
+		Scheduler: s,
+		Frame:     10 * time.Millisecond,
+		Error:     ErrMarble,
+		t:         t,
+		start:     s.Now(),
+	}
+}
+
+func (h *Harness) parse(marble string, values map[string]interface{}) ([]Event, int) {
+	h.t.Helper()
+	events, frames, e := Parse(marble, values, h.Error)
+	if e != nil {
+		h.t.Fatalf("%v in %q", e, marble)
+	}
+	return events, frames
+}
+
+// Cold creates an Observable that plays the marble diagram from the time each observer subscribes
+func (h *Harness) Cold(marble string, values map[string]interface{}) *rxgo.Observable {
+	h.t.Helper()
+	if strings.ContainsRune(marble, '^') {
+		h.t.Fatalf("rxgotest: '^' in cold marble %q", marble)
+	}
+	events, _ := h.parse(marble, values)
+	o := rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		h.play(ctx, rxgo.ClockOf(ctx).Now(), events, send)
+	})
+	o.Name = "Cold " + marble
+	return o
+}
+
+// Hot creates an Observable that plays the marble diagram on the time of the Harness, of which '^' is frame 0.
+// An observer receives only events at or after the time it subscribes
+func (h *Harness) Hot(marble string, values map[string]interface{}) *rxgo.Observable {
+	h.t.Helper()
+	events, _ := h.parse(marble, values)
+	o := rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		h.play(ctx, h.start, events, send)
+	})
+	o.Name = "Hot " + marble
+	return o
+}
+
+// send events at their time from start, skipping items before now
+func (h *Harness) play(ctx context.Context, start time.Time, events []Event, send func(x interface{}) (endSignal bool)) {
+	clock := rxgo.ClockOf(ctx)
+	for _, e := range events {
+		due := start.Add(time.Duration(e.Frame) * h.Frame)
+		now := clock.Now()
+		if due.Before(now) {
+			if e.Kind == rxgo.OnCompletedNotification {
+				return
+			}
+			continue
+		}
+		if due.After(now) {
+			timer := clock.NewTimer(due.Sub(now))
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		switch e.Kind {
+		case rxgo.OnNextNotification:
+			if send(e.Value) {
+				return
+			}
+		case rxgo.OnErrorNotification:
+			if send(e.Err) {
+				return
+			}
+		default:
+			return
+		}
+	}
+	<-ctx.Done()
+}
+
+// Run subscribes the Observable on the TestScheduler, and advances virtual time frame by frame
+// until the Observable completed or the frame `until` passed. It returns the events received by then.
+// An event is at the frame when the observer receives it, which is the frame of the timer sending it,
+// for the TestScheduler moves to the next timer after the observer received the items
+func (h *Harness) Run(o *rxgo.Observable, until int) []Event {
+	var mu sync.Mutex
+	var events []Event
+	record := func(n rxgo.Notification) {
+		mu.Lock()
+		defer mu.Unlock()
+		frame := int(h.Scheduler.Now().Sub(h.start) / h.Frame)
+		events = append(events, Event{frame, n})
+	}
+	s := o.SubscribeAsync(rxgo.ObserverMonitor{
+		Next: func(x interface{}) {
+			record(rxgo.Notification{Kind: rxgo.OnNextNotification, Value: x})
+		},
+		Error: func(e error) {
+			record(rxgo.Notification{Kind: rxgo.OnErrorNotification, Err: e})
+		},
+		Completed: func() {
+			record(rxgo.Notification{Kind: rxgo.OnCompletedNotification})
+		},
+		Context: func() context.Context {
+			return rxgo.WithClock(context.Background(), h.Scheduler)
+		},
+	})
+	done := func() bool {
+		select {
+		case <-s.Done():
+			return true
+		default:
+			return false
+		}
+	}
+	// frames are counted from the start, so that AdvanceBy of the test before Run does not shift them
+	frame := int(h.Scheduler.Now().Sub(h.start) / h.Frame)
+	for h.Scheduler.AdvanceBy(0); !done() && frame < until; {
+		frame++
+		h.Scheduler.AdvanceTo(h.start.Add(time.Duration(frame) * h.Frame))
+	}
+	s.Dispose()
+	s.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return events
+}
+
+// Expect runs the Observable for the frames of the expected marble diagram and one more,
+// and reports the difference of events to the test if they are not the expected ones
+func (h *Harness) Expect(o *rxgo.Observable, marble string, values map[string]interface{}) bool {
+	h.t.Helper()
+	expected, frames := h.parse(marble, values)
+	actual := h.Run(o, frames)
+	if equalEvents(expected, actual) {
+		return true
+	}
+	h.t.Errorf("marbles differ\nexpected: %s\nactual:   %s\nevents:\n%s",
+		Format(expected, values), Format(actual, values), diffEvents(expected, actual))
+	return false
+}
+
+func equalEvents(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalEvent(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalEvent(a, b Event) bool {
+	if a.Frame != b.Frame || a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case rxgo.OnNextNotification:
+		return reflect.DeepEqual(a.Value, b.Value)
+	case rxgo.OnErrorNotification:
+		// errors such as FlowableError are compared by message
+		return errors.Is(b.Err, a.Err) || a.Err != nil && b.Err != nil && a.Err.Error() == b.Err.Error()
+	}
+	return true
+}
+
+// events in lines, the expected one is marked "-" and the actual one "+" if they are different
+func diffEvents(expected, actual []Event) string {
+	var sb strings.Builder
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i < len(expected) && i < len(actual) && equalEvent(expected[i], actual[i]):
+			fmt.Fprintf(&sb, "    %v\n", expected[i])
+		default:
+			if i < len(expected) {
+				fmt.Fprintf(&sb, "  - %v\n", expected[i])
+			}
+			if i < len(actual) {
+				fmt.Fprintf(&sb, "  + %v\n", actual[i])
+			}
+		}
+	}
+	return sb.String()
+}
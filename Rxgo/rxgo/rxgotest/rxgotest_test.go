@@ -0,0 +1,145 @@
+package rxgotest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gitee.com/li-jia666/rxgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// records failures instead of failing the test
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+}
+
+func TestParse(t *testing.T) {
+	ee := errors.New("Any")
+	events, frames, e := Parse("-a-(bc)- d|", map[string]interface{}{"a": 1}, ee)
+	assert.NoError(t, e, "Parse Test Error!")
+	assert.Equal(t, 7, frames, "Parse Test Error!")
+	assert.Equal(t, []Event{
+		{1, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: 1}},
+		{3, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: "b"}},
+		{3, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: "c"}},
+		{5, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: "d"}},
+		{6, rxgo.Notification{Kind: rxgo.OnCompletedNotification}},
+	}, events, "Parse Test Error!")
+
+	// the last error completes, and '^' is frame 0
+	events, frames, e = Parse("a-^-#", nil, ee)
+	assert.NoError(t, e, "Parse Test Error!")
+	assert.Equal(t, 3, frames, "Parse Test Error!")
+	assert.Equal(t, []Event{
+		{-2, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: "a"}},
+		{2, rxgo.Notification{Kind: rxgo.OnErrorNotification, Err: ee}},
+		{2, rxgo.Notification{Kind: rxgo.OnCompletedNotification}},
+	}, events, "Parse Test Error!")
+
+	for _, bad := range []string{"-a-|b", "(a(b))", "(a", "a)", "^-^", "(a-b)"} {
+		_, _, e = Parse(bad, nil, ee)
+		assert.Error(t, e, "Parse "+bad+" Test Error!")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, marble := range []string{"-a-(bc)-d|", "--#", "-a#-b|", "", "---a"} {
+		events, _, _ := Parse(marble, nil, ErrMarble)
+		assert.Equal(t, strings.TrimRight(marble, "-"), Format(events, nil), "Format Test Error!")
+	}
+	events := []Event{
+		{0, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: 1}},
+		{1, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: 10}},
+		{2, rxgo.Notification{Kind: rxgo.OnNextNotification, Value: 20}},
+	}
+	assert.Equal(t, "1{10}x", Format(events, map[string]interface{}{"x": 20}), "Format Test Error!")
+}
+
+func TestCold(t *testing.T) {
+	h := New(t)
+	src := h.Cold("-a-b-c-|", nil)
+	h.Expect(src, "-a-b-c-|", nil)
+	// subscribed again at frame 7, when the first one completed
+	h.Expect(src.Map(strings.ToUpper), "--------A-B-C-|", nil)
+
+	h = New(t)
+	h.Expect(h.Cold("-1-2-#", map[string]interface{}{"1": 1, "2": 2}).Map(func(x int) int {
+		return x * 10
+	}), "-x-y-#", map[string]interface{}{"x": 10, "y": 20})
+}
+
+func TestHot(t *testing.T) {
+	h := New(t)
+	src := h.Hot("-a-^-b-c-|", nil)
+	h.Expect(src, "--b-c-|", nil)
+
+	// subscribed late
+	h = New(t)
+	src = h.Hot("^-b-c-d-|", nil)
+	h.Scheduler.AdvanceBy(3 * h.Frame)
+	h.Expect(src, "----c-d-|", nil)
+}
+
+func TestOperators(t *testing.T) {
+	h := New(t)
+	h.Expect(h.Cold("-a-b-c-|", nil).Delay(2*h.Frame), "---a-b-(c|)", nil)
+
+	h = New(t)
+	h.Expect(h.Cold("-ab---c--|", nil).Debounce(2*h.Frame), "----b---c|", nil)
+
+	h = New(t)
+	h.Expect(h.Cold("-a-b-c-d-|", nil).Take(2), "-a-(b|)", nil)
+
+	h = New(t)
+	h.Expect(rxgo.Merge(h.Cold("-a---b|", nil), h.Cold("--c-d-|", nil)), "-ac-db|", nil)
+}
+
+func TestSlowOperators(t *testing.T) {
+	// busy operators delay items on the wall clock, not on virtual time
+	busy := func(x string) string {
+		for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+		}
+		return strings.ToUpper(x)
+	}
+	h := New(t)
+	h.Expect(h.Cold("-a-b-c-|", nil).Map(busy).Delay(h.Frame).Map(busy), "--A-B-C(|)", nil)
+
+	h = New(t)
+	src := h.Hot("^-a-b-c-|", nil).Map(busy).SubscribeOn(rxgo.ThreadingIO)
+	h.Expect(src, "--A-B-C-|", nil)
+}
+
+func TestExpectFailure(t *testing.T) {
+	ft := &fakeT{}
+	h := New(ft)
+	ok := h.Expect(h.Cold("-a-b-|", nil), "-a-c-|", nil)
+	assert.False(t, ok, "Expect Test Error!")
+	assert.Len(t, ft.errors, 1, "Expect Test Error!")
+	assert.Equal(t, `marbles differ
+expected: -a-c-|
+actual:   -a-b-|
+events:
+    1: OnNext("a")
+  - 3: OnNext("c")
+  + 3: OnNext("b")
+    5: OnCompleted()
+`, ft.errors[0], "Expect Test Error!")
+
+	ft = &fakeT{}
+	h = New(ft)
+	h.Cold("^-a", nil)
+	assert.Len(t, ft.errors, 1, "Cold Test Error!")
+}
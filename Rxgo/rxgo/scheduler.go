@@ -0,0 +1,214 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+)
+
+// Scheduler decides which goroutine runs the task of an Observable
+type Scheduler interface {
+	Schedule(task func())
+}
+
+// each task served by one goroutine in a limited group
+type poolScheduler struct {
+	tokens chan struct{}
+}
+
+// NewPoolScheduler creates a Scheduler which runs at most size tasks at the same time.
+// Schedule blocks until a goroutine in the group is free. size <= 0 means runtime.NumCPU().
+// A task of an operator keeps its slot until it returns, except that it gives the slot back while it waits for
+// a full downstream, and takes a slot again before it goes on
+func NewPoolScheduler(size int) Scheduler {
+	if size <= 0 {
+		size = runtime.NumCPU()
+	}
+	return &poolScheduler{tokens: make(chan struct{}, size)}
+}
+
+func (s *poolScheduler) Schedule(task func()) {
+	s.tokens <- struct{}{}
+	go func() {
+		defer func() { <-s.tokens }()
+		task()
+	}()
+}
+
+// the slot of a computing task, and the outflow the task sends items to
+type computingSlot struct {
+	tokens chan struct{}
+	out    chan interface{}
+}
+
+type slotKey struct{}
+
+// scheduleComputing runs task on sched. If sched is a pool, ctx of task carries the slot of task, see yieldSlot
+func scheduleComputing(ctx context.Context, sched Scheduler, out chan interface{}, task func(ctx context.Context)) {
+	if ps, ok := sched.(*poolScheduler); ok {
+		ctx = context.WithValue(ctx, slotKey{}, computingSlot{ps.tokens, out})
+	}
+	sched.Schedule(func() {
+		task(ctx)
+	})
+}
+
+// yieldSlot gives back the slot of the task waiting for the full out with ctx, and returns the function taking
+// a slot again. Otherwise tasks holding all slots wait for the receiver of out, which may need a slot to go on
+func yieldSlot(ctx context.Context, out chan interface{}) (resume func()) {
+	if slot, ok := ctx.Value(slotKey{}).(computingSlot); ok && slot.out == out {
+		<-slot.tokens
+		return func() {
+			slot.tokens <- struct{}{}
+		}
+	}
+	return func() {}
+}
+
+// runOn runs task on sched and waits for it
+func runOn(sched Scheduler, task func()) {
+	done := make(chan struct{})
+	sched.Schedule(func() {
+		defer close(done)
+		task()
+	})
+	<-done
+}
+
+// the group shared by all Observables with ThreadingComputing
+var computingScheduler atomic.Value
+
+func init() {
+	computingScheduler.Store(NewPoolScheduler(runtime.NumCPU()))
+}
+
+// ComputingScheduler returns the Scheduler shared by all Observables with ThreadingComputing
+func ComputingScheduler() Scheduler {
+	return computingScheduler.Load().(Scheduler)
+}
+
+// SetComputingPoolSize resets the size of the group shared by all Observables with ThreadingComputing.
+// It takes effect on Observables connected after that
+func SetComputingPoolSize(size int) {
+	computingScheduler.Store(NewPoolScheduler(size))
+}
+
+// SetConcurrency returns a copy of the Observable that limits the number of items processed at the same time
+// with ThreadingIO or ThreadingComputing. 0 means no limit
+func (o *Observable) SetConcurrency(n uint) *Observable {
+	o = o.clone()
+	o.concurrency = n
+	return o
+}
+
+// per-operator concurrency limit, nil means no limit
+type limiter chan struct{}
+
+// inflightLimit limits the tasks of o started by its dispatcher and not finished yet. It is the concurrency of o,
+// or the size of the computing group with ThreadingComputing, so that tasks waiting outside the group,
+// such as FlatMap draining inner Observables, never pile up
+func (o *Observable) inflightLimit(sched Scheduler) limiter {
+	n := o.concurrency
+	if n == 0 && o.threading == ThreadingComputing {
+		n = uint(runtime.NumCPU())
+		if ps, ok := sched.(*poolScheduler); ok {
+			n = uint(cap(ps.tokens))
+		}
+	}
+	return newLimiter(n)
+}
+
+func newLimiter(n uint) limiter {
+	if n == 0 {
+		return nil
+	}
+	return make(limiter, n)
+}
+
+// take a slot, it returns false if ctx is done, even though a slot is released at the same time
+func (l limiter) acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l <- struct{}{}:
+		if ctx.Err() == nil {
+			return true
+		}
+		<-l
+	case <-ctx.Done():
+	}
+	return false
+}
+
+func (l limiter) release() {
+	if l != nil {
+		<-l
+	}
+}
+
+// sequencer emits results of concurrent items in the order of input items.
+// Each item sends its results to its own slot, and the slots are drained one by one.
+// A nil sequencer sends results to out directly
+type sequencer struct {
+	order chan chan interface{}
+	done  chan struct{}
+}
+
+func newSequencer(ctx context.Context, o *Observable, out chan interface{}) *sequencer {
+	if !o.ordered {
+		return nil
+	}
+	s := &sequencer{
+		order: make(chan chan interface{}, o.concurrency),
+		done:  make(chan struct{}),
+	}
+	goFlow(ctx, func() {
+		defer close(s.done)
+		for ch := range s.order {
+			for x := range ch {
+				select {
+				case out <- x:
+				case <-ctx.Done():
+				}
+			}
+		}
+	})
+	return s
+}
+
+// get a slot for results of next item
+func (s *sequencer) slot(out chan interface{}) chan interface{} {
+	if s == nil {
+		return out
+	}
+	ch := make(chan interface{}, 1)
+	s.order <- ch
+	return ch
+}
+
+// all results of the item in slot ch are sent
+func (s *sequencer) complete(ch chan interface{}) {
+	if s != nil {
+		close(ch)
+	}
+}
+
+// wait for all slots drained
+func (s *sequencer) wait() {
+	if s != nil {
+		close(s.order)
+		<-s.done
+	}
+}
+
+// SchedulerFunc is an adapter to allow the use of a func, such as an executor, as a Scheduler
+type SchedulerFunc func(task func())
+
+func (f SchedulerFunc) Schedule(task func()) {
+	f(task)
+}
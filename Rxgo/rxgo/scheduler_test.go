@@ -0,0 +1,214 @@
+package rxgo
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// track the max number of items processed at the same time
+type concurrencyProbe struct {
+	active, max int32
+}
+
+func (p *concurrencyProbe) enter() {
+	n := atomic.AddInt32(&p.active, 1)
+	for m := atomic.LoadInt32(&p.max); n > m; m = atomic.LoadInt32(&p.max) {
+		if atomic.CompareAndSwapInt32(&p.max, m, n) {
+			break
+		}
+	}
+}
+
+func (p *concurrencyProbe) leave() {
+	atomic.AddInt32(&p.active, -1)
+}
+
+func (p *concurrencyProbe) work(x int) int {
+	p.enter()
+	defer p.leave()
+	runtime.Gosched()
+	return x * 2
+}
+
+func TestComputingPoolSize(t *testing.T) {
+	SetComputingPoolSize(2)
+	defer SetComputingPoolSize(runtime.NumCPU())
+
+	var p concurrencyProbe
+	res := []int{}
+	Range(0, 100).Map(p.work).SubscribeOn(ThreadingComputing).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Len(t, res, 100, "Computing Test Error!")
+	assert.True(t, p.max <= 2, "Computing pool exceeded: %d", p.max)
+}
+
+func TestComputingNestedFlatMap(t *testing.T) {
+	SetComputingPoolSize(2)
+	defer SetComputingPoolSize(runtime.NumCPU())
+
+	// outer tasks waiting for inner ones must not hold all slots of the pool
+	var n int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Range(0, 10).FlatMap(func(x int) *Observable {
+			return Range(0, 300).Map(func(y int) int { return y }).SubscribeOn(ThreadingComputing)
+		}).SubscribeOn(ThreadingComputing).Subscribe(func(x int) {
+			atomic.AddInt64(&n, 1)
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Computing FlatMap deadlocked!")
+	}
+	assert.Equal(t, int64(3000), atomic.LoadInt64(&n), "Computing FlatMap Test Error!")
+}
+
+func TestComputingSlowSubscriber(t *testing.T) {
+	SetComputingPoolSize(2)
+	defer SetComputingPoolSize(runtime.NumCPU())
+
+	// tasks waiting for a slow subscriber keep their slots, so that no more goroutines are started
+	base := runtime.NumGoroutine()
+	max, n := 0, 0
+	Range(0, 2000).Map(func(x int) int {
+		return x
+	}).SubscribeOn(ThreadingComputing).Subscribe(func(x int) {
+		if n++; n%50 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if g := runtime.NumGoroutine(); g > max {
+			max = g
+		}
+	})
+
+	assert.Equal(t, 2000, n, "Computing Slow Subscriber Test Error!")
+	assert.True(t, max-base < 20, "Goroutines not bounded: %d", max-base)
+}
+
+func TestComputingChainSlowSubscriber(t *testing.T) {
+	SetComputingPoolSize(2)
+	defer SetComputingPoolSize(runtime.NumCPU())
+
+	// tasks of one operator waiting to send must not hold the slots the next operator needs to receive
+	var n int64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Range(0, 2000).Map(func(x int) int {
+			return x
+		}).SubscribeOn(ThreadingComputing).Map(func(x int) int {
+			return x
+		}).SubscribeOn(ThreadingComputing).Subscribe(func(x int) {
+			if atomic.AddInt64(&n, 1)%100 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Computing chain deadlocked!")
+	}
+	assert.Equal(t, int64(2000), atomic.LoadInt64(&n), "Computing Chain Test Error!")
+}
+
+func TestSetConcurrency(t *testing.T) {
+	var p concurrencyProbe
+	res := []int{}
+	Range(0, 100).Map(p.work).SubscribeOn(ThreadingIO).SetConcurrency(3).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Len(t, res, 100, "Concurrency Test Error!")
+	assert.True(t, p.max <= 3, "Concurrency exceeded: %d", p.max)
+}
+
+func TestPoolSchedulerFilter(t *testing.T) {
+	res := []int{}
+	Range(0, 10).Filter(func(x int) bool {
+		return x%2 == 0
+	}).SubscribeOn(ThreadingComputing).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.ElementsMatch(t, []int{0, 2, 4, 6, 8}, res, "Computing Filter Test Error!")
+}
+
+func benchmarkMap(b *testing.B, t ThreadModel) {
+	var p concurrencyProbe
+	for i := 0; i < b.N; i++ {
+		Range(0, 10000).Map(p.work).SubscribeOn(t).Subscribe(func(x int) {})
+	}
+	b.ReportMetric(float64(p.max), "max-concurrent-items")
+}
+
+// the unbounded behaviour: one goroutine for each item
+func BenchmarkMapThreadingIO(b *testing.B) {
+	benchmarkMap(b, ThreadingIO)
+}
+
+func BenchmarkMapThreadingComputing(b *testing.B) {
+	benchmarkMap(b, ThreadingComputing)
+}
+
+func BenchmarkMapThreadingDefault(b *testing.B) {
+	benchmarkMap(b, ThreadingDefault)
+}
+
+func TestSubscribeOnOrdered(t *testing.T) {
+	var p concurrencyProbe
+	res := []int{}
+	Range(0, 20).Map(func(x int) int {
+		// later items finish earlier
+		time.Sleep(time.Duration(20-x) * time.Millisecond)
+		return p.work(x)
+	}).SubscribeOnOrdered(4).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	expected := []int{}
+	for i := 0; i < 20; i++ {
+		expected = append(expected, i*2)
+	}
+	assert.Equal(t, expected, res, "Ordered Map Test Error!")
+	assert.True(t, p.max <= 4, "Concurrency exceeded: %d", p.max)
+}
+
+func TestSubscribeOnOrderedFilter(t *testing.T) {
+	res := []int{}
+	Range(0, 10).Filter(func(x int) bool {
+		time.Sleep(time.Duration(10-x) * time.Millisecond)
+		return x%3 == 0
+	}).SubscribeOnOrdered(0).Subscribe(func(x int) {
+		res = append(res, x)
+	})
+
+	assert.Equal(t, []int{0, 3, 6, 9}, res, "Ordered Filter Test Error!")
+}
+
+func TestSubscribeOnOrderedFlatMap(t *testing.T) {
+	ee := errors.New("Any")
+	res := []interface{}{}
+	Just(3, ee, 2, 1).FlatMap(func(x int) *Observable {
+		time.Sleep(time.Duration(x) * 10 * time.Millisecond)
+		return Just(x, x*10)
+	}).SubscribeOnOrdered(3).Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			res = append(res, e)
+		},
+	})
+
+	assert.Equal(t, []interface{}{3, 30, ee, 2, 20, 1, 10}, res, "Ordered FlatMap Test Error!")
+}
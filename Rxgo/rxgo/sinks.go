@@ -0,0 +1,235 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WriteLines subscribes the Observable and writes each item formatted by format, such as "%v" or "%d", in a line.
+// An empty format is "%v". It blocks until the Observable completed, and returns the first error of the stream
+// or of writing, which unsubscribes the Observable, or the error of ctx if it is cancelled
+func (o *Observable) WriteLines(ctx context.Context, w io.Writer, format string) error {
+	if format == "" {
+		format = "%v"
+	}
+	return o.writeTo(ctx, func(x interface{}) error {
+		_, e := fmt.Fprintf(w, format+"\n", x)
+		return e
+	}, nil)
+}
+
+// WriteJSONLines writes each item in JSON in a line, like WriteLines
+func (o *Observable) WriteJSONLines(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return o.writeTo(ctx, func(x interface{}) error {
+		return enc.Encode(x)
+	}, nil)
+}
+
+// WriteCSV writes each item as a CSV record, like WriteLines. A []string is written as it is,
+// elements of other slices and arrays are formatted by fmt.Sprint, and other items are records of one field
+func (o *Observable) WriteCSV(ctx context.Context, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	return o.writeTo(ctx, func(x interface{}) error {
+		return cw.Write(csvRecord(x))
+	}, func() error {
+		cw.Flush()
+		return cw.Error()
+	})
+}
+
+func csvRecord(x interface{}) []string {
+	if record, ok := x.([]string); ok {
+		return record
+	}
+	xv := reflect.ValueOf(x)
+	if xv.Kind() != reflect.Slice && xv.Kind() != reflect.Array {
+		return []string{fmt.Sprint(x)}
+	}
+	record := make([]string, xv.Len())
+	for i := range record {
+		record[i] = fmt.Sprint(xv.Index(i).Interface())
+	}
+	return record
+}
+
+// subscribe o and write items until the first error, then flush if flush is not nil
+func (o *Observable) writeTo(ctx context.Context, write func(x interface{}) error, flush func() error) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fail := func(e error) {
+		if err == nil {
+			err = e
+		}
+		cancel()
+	}
+	o.Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			if e := write(x); e != nil {
+				fail(e)
+			}
+		},
+		Error: fail,
+		Context: func() context.Context {
+			return ctx
+		},
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if flush != nil {
+		if e := flush(); err == nil {
+			err = e
+		}
+	}
+	return
+}
+
+// StreamFormat is the format of a StreamHandler response
+type StreamFormat uint
+
+const (
+	StreamNDJSON StreamFormat = iota // newline delimited JSON, sent with chunked encoding
+	StreamSSE                        // Server-Sent Events
+)
+
+// StreamHandler is an http.Handler that subscribes the Observable for each request, and streams its items
+// in JSON to the response. An error is sent as {"error": message}, in an "error" event of SSE.
+// The subscription is cancelled when the client disconnects or writing fails.
+// Items are flushed every FlushInterval, after each item if it is 0, or only when the Observable completed if it is negative
+type StreamHandler struct {
+	Observable    *Observable
+	Format        StreamFormat
+	FlushInterval time.Duration
+	// Event is the event name of SSE items, the default "message" event if empty.
+	// A "complete" event is sent when the Observable completed, so that the client will not reconnect
+	Event string
+}
+
+var _ http.Handler = &StreamHandler{}
+
+// NewStreamHandler creates a StreamHandler flushing after each item
+func NewStreamHandler(o *Observable, format StreamFormat) *StreamHandler {
+	return &StreamHandler{Observable: o, Format: format}
+}
+
+type streamError struct {
+	Error string `json:"error"`
+}
+
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	if h.Format == StreamSSE {
+		header.Set("Content-Type", "text/event-stream")
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+	}
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	// writes of items and flushes of the ticker
+	var mu sync.Mutex
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if h.FlushInterval > 0 {
+		ticker := time.NewTicker(h.FlushInterval)
+		done, exited := make(chan struct{}), make(chan struct{})
+		defer func() {
+			ticker.Stop()
+			close(done)
+			<-exited
+		}()
+		go func() {
+			defer close(exited)
+			for {
+				select {
+				case <-ticker.C:
+					mu.Lock()
+					flush()
+					mu.Unlock()
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	write := func(x interface{}) error {
+		event := h.Event
+		if e, ok := x.(error); ok {
+			x, event = streamError{e.Error()}, "error"
+		}
+		data, e := json.Marshal(x)
+		if e != nil {
+			data, _ = json.Marshal(streamError{e.Error()})
+			event = "error"
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if e = h.writeEvent(w, event, data); e == nil && h.FlushInterval == 0 {
+			flush()
+		}
+		return e
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var werr error
+	h.Observable.Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			if werr = write(x); werr != nil {
+				cancel()
+			}
+		},
+		Error: func(e error) {
+			if werr = write(e); werr != nil {
+				cancel()
+			}
+		},
+		Completed: func() {
+			if h.Format == StreamSSE {
+				mu.Lock()
+				werr = h.writeEvent(w, "complete", []byte("{}"))
+				mu.Unlock()
+			}
+		},
+		Context: func() context.Context {
+			return ctx
+		},
+	})
+	if werr == nil && ctx.Err() == nil {
+		mu.Lock()
+		flush()
+		mu.Unlock()
+	}
+}
+
+func (h *StreamHandler) writeEvent(w io.Writer, event string, data []byte) (e error) {
+	if h.Format != StreamSSE {
+		_, e = fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+	if event != "" {
+		if _, e = fmt.Fprintf(w, "event: %s\n", event); e != nil {
+			return
+		}
+	}
+	_, e = fmt.Fprintf(w, "data: %s\n\n", data)
+	return
+}
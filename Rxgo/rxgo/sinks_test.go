@@ -0,0 +1,122 @@
+package rxgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct {
+	n int // bytes accepted before failing
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		return 0, errors.New("Write failed")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteLines(t *testing.T) {
+	var buf bytes.Buffer
+	e := Just(1, 2, 3).WriteLines(context.Background(), &buf, "")
+	assert.NoError(t, e, "WriteLines Test Error!")
+	assert.Equal(t, "1\n2\n3\n", buf.String(), "WriteLines Test Error!")
+
+	buf.Reset()
+	e = Just(1, 2).WriteLines(context.Background(), &buf, "item %03d")
+	assert.NoError(t, e, "WriteLines Test Error!")
+	assert.Equal(t, "item 001\nitem 002\n", buf.String(), "WriteLines Test Error!")
+
+	// error of the stream
+	ee := errors.New("Any")
+	buf.Reset()
+	e = Just(1, ee, 3).WriteLines(context.Background(), &buf, "")
+	assert.Equal(t, ee, e, "WriteLines error Test Error!")
+	assert.Equal(t, "1\n", buf.String(), "WriteLines error Test Error!")
+
+	// error of writing cancels the source
+	var sent int64
+	e = countingSource(&sent).WriteLines(context.Background(), &failingWriter{n: 4}, "")
+	assert.EqualError(t, e, "Write failed", "WriteLines error Test Error!")
+	assert.True(t, atomic.LoadInt64(&sent) < 100, "WriteLines does not unsubscribe!")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e = Never().WriteLines(ctx, &buf, "")
+	assert.Equal(t, context.Canceled, e, "WriteLines cancel Test Error!")
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	var buf bytes.Buffer
+	e := Just(point{1, 2}, "a", 3).WriteJSONLines(context.Background(), &buf)
+	assert.NoError(t, e, "WriteJSONLines Test Error!")
+	assert.Equal(t, "{\"X\":1,\"Y\":2}\n\"a\"\n3\n", buf.String(), "WriteJSONLines Test Error!")
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	e := Just([]string{"a", "b,c"}, []int{1, 2}, 3).WriteCSV(context.Background(), &buf)
+	assert.NoError(t, e, "WriteCSV Test Error!")
+	assert.Equal(t, "a,\"b,c\"\n1,2\n3\n", buf.String(), "WriteCSV Test Error!")
+
+	e = Just("a").WriteCSV(context.Background(), &failingWriter{})
+	assert.EqualError(t, e, "Write failed", "WriteCSV error Test Error!")
+}
+
+func TestStreamHandler(t *testing.T) {
+	ee := errors.New("Any")
+	rec := httptest.NewRecorder()
+	NewStreamHandler(Just(1, "a", ee), StreamNDJSON).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"), "StreamHandler Test Error!")
+	assert.Equal(t, "1\n\"a\"\n{\"error\":\"Any\"}\n", rec.Body.String(), "StreamHandler NDJSON Test Error!")
+	assert.True(t, rec.Flushed, "StreamHandler Test Error!")
+
+	rec = httptest.NewRecorder()
+	h := NewStreamHandler(Just(1, ee), StreamSSE)
+	h.Event = "number"
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"), "StreamHandler Test Error!")
+	assert.Equal(t, "event: number\ndata: 1\n\nevent: error\ndata: {\"error\":\"Any\"}\n\nevent: complete\ndata: {}\n\n",
+		rec.Body.String(), "StreamHandler SSE Test Error!")
+}
+
+func TestStreamHandlerDisconnect(t *testing.T) {
+	stopped := make(chan struct{})
+	o := Interval(time.Millisecond).DoFinally(func() { close(stopped) })
+	srv := httptest.NewServer(NewStreamHandler(o, StreamNDJSON))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	resp, e := http.DefaultClient.Do(req)
+	assert.NoError(t, e, "StreamHandler Test Error!")
+	// items are flushed one by one
+	lines := bufio.NewReader(resp.Body)
+	for i := 0; i < 3; i++ {
+		line, e := lines.ReadString('\n')
+		assert.NoError(t, e, "StreamHandler Test Error!")
+		assert.Equal(t, fmt.Sprintln(i), line, "StreamHandler Test Error!")
+	}
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("StreamHandler does not unsubscribe when the client disconnected!")
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FromReader creates an Observable that emits tokens of r as strings, which are split by the split function
+// such as bufio.ScanWords. Lines are emitted if split is nil.
+// r is closed when the subscription is cancelled if it is an io.Closer, and a read error is sent as FlowableError
+func FromReader(r io.Reader, split bufio.SplitFunc) *Observable {
+	return newScanObservable("FromReader", func() (io.Reader, func() error, error) {
+		return r, nil, nil
+	}, split, 0, tokenString)
+}
+
+// FromReaderRecords creates an Observable that emits records of r separated by delim as strings, like FromReader
+func FromReaderRecords(r io.Reader, delim byte) *Observable {
+	o := FromReader(r, splitRecords(delim))
+	o.Name = "FromReaderRecords"
+	return o
+}
+
+// FromReaderChunks creates an Observable that emits []byte chunks of r of size bytes, the last one may be shorter.
+// It reads r like FromReader
+func FromReaderChunks(r io.Reader, size int) *Observable {
+	if size <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return newScanObservable("FromReaderChunks", func() (io.Reader, func() error, error) {
+		return r, nil, nil
+	}, splitChunks(size), size, tokenBytes)
+}
+
+// FromFile creates an Observable that opens the file for each subscription, and emits its tokens like FromReader.
+// The file is closed when the Observable completed or cancelled, and an error of opening is sent as FlowableError
+func FromFile(path string, split bufio.SplitFunc) *Observable {
+	return newScanObservable("FromFile", func() (io.Reader, func() error, error) {
+		f, e := os.Open(path)
+		if e != nil {
+			return nil, nil, e
+		}
+		return f, f.Close, nil
+	}, split, 0, tokenString)
+}
+
+// FromScanner creates an Observable that emits tokens of the scanner as strings, and sends its error as FlowableError.
+// The reader of the scanner is not closed
+func FromScanner(sc *bufio.Scanner) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for sc.Scan() {
+			if send(sc.Text()) {
+				return
+			}
+		}
+		if e := sc.Err(); e != nil {
+			send(FlowableError{Err: e})
+		}
+	})
+	o.Name = "FromScanner"
+	return o
+}
+
+func tokenString(token []byte) interface{} {
+	return string(token)
+}
+
+func tokenBytes(token []byte) interface{} {
+	return append([]byte(nil), token...)
+}
+
+// open returns the reader and a function closing it, which is nil if the reader is not owned
+func newScanObservable(name string, open func() (io.Reader, func() error, error), split bufio.SplitFunc, size int, emit func(token []byte) interface{}) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		r, closer, e := open()
+		if e != nil {
+			send(FlowableError{Err: e})
+			return
+		}
+		if closer == nil {
+			if c, ok := r.(io.Closer); ok {
+				defer closeOnDone(ctx, c.Close)()
+			}
+		} else {
+			defer closer()
+			defer closeOnDone(ctx, closer)()
+		}
+
+		sc := bufio.NewScanner(r)
+		if size > bufio.MaxScanTokenSize {
+			sc.Buffer(make([]byte, 0, size), size)
+		}
+		if split != nil {
+			sc.Split(split)
+		}
+		for sc.Scan() {
+			if send(emit(sc.Bytes())) {
+				return
+			}
+		}
+		// not an error of closing the reader on cancellation
+		if e := sc.Err(); e != nil && ctx.Err() == nil {
+			send(FlowableError{Err: e})
+		}
+	})
+	o.Name = name
+	return o
+}
+
+// call closeFn when ctx is done, so that a blocked read returns. It returns a function to stop waiting,
+// after which closeFn is not called
+func closeOnDone(ctx context.Context, closeFn func() error) (stop func()) {
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			closeFn()
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+func splitRecords(delim byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.IndexByte(data, delim); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+func splitChunks(size int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if len(data) >= size {
+			return size, data[:size], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// FromTicker creates an Observable that emits the time every period
+func FromTicker(period time.Duration) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		timer := newPeriodTimer(ctx, period)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				return
+			}
+			if send(timer.clock.Now()) {
+				return
+			}
+			timer.advance()
+		}
+	})
+	o.Name = "FromTicker"
+	return o
+}
+
+// FileOp is the change of a file in a FileEvent
+type FileOp uint
+
+const (
+	FileCreated FileOp = iota
+	FileModified
+	FileRemoved
+)
+
+func (op FileOp) String() string {
+	switch op {
+	case FileCreated:
+		return "created"
+	case FileModified:
+		return "modified"
+	case FileRemoved:
+		return "removed"
+	}
+	return fmt.Sprint("FileOp(", uint(op), ")")
+}
+
+// FileEvent is a change of a file in the directory, emitted by FromDirectoryWatch
+type FileEvent struct {
+	Op   FileOp
+	Path string
+	Info os.FileInfo // nil if the file is removed
+}
+
+// FromDirectoryWatch creates an Observable that reads the directory every interval, and emits a FileEvent
+// for each entry created, modified (by size or modification time) or removed since the previous reading.
+// The first reading at subscription emits nothing. Errors of reading are sent as FlowableError
+func FromDirectoryWatch(dir string, interval time.Duration) *Observable {
+	o := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		timer := newPeriodTimer(ctx, interval)
+		defer timer.Stop()
+		last, _ := readDirInfo(dir)
+		for {
+			select {
+			case <-timer.C():
+			case <-ctx.Done():
+				return
+			}
+			timer.advance()
+			infos, e := readDirInfo(dir)
+			if e != nil {
+				if send(FlowableError{Err: e}) {
+					return
+				}
+				continue
+			}
+			for _, ev := range diffDirInfo(dir, last, infos) {
+				if send(ev) {
+					return
+				}
+			}
+			last = infos
+		}
+	})
+	o.Name = "FromDirectoryWatch"
+	return o
+}
+
+func readDirInfo(dir string) (map[string]os.FileInfo, error) {
+	entries, e := os.ReadDir(dir)
+	if e != nil {
+		return nil, e
+	}
+	infos := make(map[string]os.FileInfo, len(entries))
+	for _, entry := range entries {
+		if info, e := entry.Info(); e == nil {
+			infos[entry.Name()] = info
+		}
+	}
+	return infos, nil
+}
+
+// events from last to now, in order of names
+func diffDirInfo(dir string, last, now map[string]os.FileInfo) (events []FileEvent) {
+	for name, info := range now {
+		old, ok := last[name]
+		switch {
+		case !ok:
+			events = append(events, FileEvent{FileCreated, filepath.Join(dir, name), info})
+		case old.Size() != info.Size() || !old.ModTime().Equal(info.ModTime()):
+			events = append(events, FileEvent{FileModified, filepath.Join(dir, name), info})
+		}
+	}
+	for name := range last {
+		if _, ok := now[name]; !ok {
+			events = append(events, FileEvent{FileRemoved, filepath.Join(dir, name), nil})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return
+}
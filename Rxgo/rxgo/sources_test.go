@@ -0,0 +1,109 @@
+package rxgo
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromReader(t *testing.T) {
+	res, completed := collectAll(FromReader(strings.NewReader("a b\nc\n"), nil))
+	assert.Equal(t, []interface{}{"a b", "c"}, res, "FromReader Test Error!")
+	assert.True(t, completed, "FromReader Test Error!")
+
+	res, _ = collectAll(FromReader(strings.NewReader("a b\nc"), bufio.ScanWords))
+	assert.Equal(t, []interface{}{"a", "b", "c"}, res, "FromReader Test Error!")
+
+	res, _ = collectAll(FromReaderRecords(strings.NewReader("a,b,,c"), ','))
+	assert.Equal(t, []interface{}{"a", "b", "", "c"}, res, "FromReaderRecords Test Error!")
+
+	res, _ = collectAll(FromReaderChunks(strings.NewReader("abcdefg"), 3))
+	assert.Equal(t, []interface{}{[]byte("abc"), []byte("def"), []byte("g")}, res, "FromReaderChunks Test Error!")
+
+	// read error
+	ee := errors.New("Any")
+	res, _ = collectAll(FromReader(io.MultiReader(strings.NewReader("a\n"), iotest.ErrReader(ee)), nil))
+	assert.Equal(t, []interface{}{"a", FlowableError{Err: ee}}, res, "FromReader error Test Error!")
+
+	res, _ = collectAll(FromScanner(bufio.NewScanner(strings.NewReader("x\ny"))))
+	assert.Equal(t, []interface{}{"x", "y"}, res, "FromScanner Test Error!")
+}
+
+func TestFromReaderCancel(t *testing.T) {
+	pr, pw := io.Pipe()
+	go pw.Write([]byte("a\nb"))
+	// the reader blocked after "a" is closed by Take
+	res, completed := collectAll(FromReader(pr, nil).Take(1))
+	assert.Equal(t, []interface{}{"a"}, res, "FromReader cancel Test Error!")
+	assert.True(t, completed, "FromReader cancel Test Error!")
+	_, e := pw.Write([]byte("c\n"))
+	assert.Equal(t, io.ErrClosedPipe, e, "FromReader does not close the reader!")
+}
+
+func TestFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lines.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("1\n2\n3\n"), 0644), "FromFile Test Error!")
+
+	o := FromFile(path, nil)
+	res, _ := collectAll(o)
+	assert.Equal(t, []interface{}{"1", "2", "3"}, res, "FromFile Test Error!")
+	// opened again
+	res, _ = collectAll(o.Take(1))
+	assert.Equal(t, []interface{}{"1"}, res, "FromFile Test Error!")
+
+	res, _ = collectAll(FromFile(path+".missing", nil))
+	assert.Len(t, res, 1, "FromFile Test Error!")
+	assert.True(t, errors.Is(res[0].(FlowableError).Err, os.ErrNotExist), "FromFile Test Error!")
+}
+
+func TestFromTicker(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(FromTicker(10*time.Millisecond), ts)
+	ts.AdvanceBy(25 * time.Millisecond)
+	s.Dispose()
+	s.Wait()
+
+	start := time.Unix(0, 0)
+	assert.Equal(t, []interface{}{start.Add(10 * time.Millisecond), start.Add(20 * time.Millisecond)}, v.items(), "FromTicker Test Error!")
+}
+
+func TestFromDirectoryWatch(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "keep.txt")
+	assert.NoError(t, os.WriteFile(keep, []byte("k"), 0644), "FromDirectoryWatch Test Error!")
+
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(FromDirectoryWatch(dir, 10*time.Millisecond), ts)
+	events := func() (res []string) {
+		for _, x := range v.items() {
+			ev := x.(FileEvent)
+			res = append(res, ev.Op.String()+" "+filepath.Base(ev.Path))
+		}
+		return
+	}
+	ts.AdvanceBy(10 * time.Millisecond)
+	assert.Len(t, events(), 0, "FromDirectoryWatch Test Error!")
+
+	a := filepath.Join(dir, "a.txt")
+	assert.NoError(t, os.WriteFile(a, []byte("1"), 0644), "FromDirectoryWatch Test Error!")
+	ts.AdvanceBy(10 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(a, []byte("12"), 0644), "FromDirectoryWatch Test Error!")
+	ts.AdvanceBy(10 * time.Millisecond)
+	assert.NoError(t, os.Remove(a), "FromDirectoryWatch Test Error!")
+	assert.NoError(t, os.Remove(keep), "FromDirectoryWatch Test Error!")
+	ts.AdvanceBy(10 * time.Millisecond)
+	s.Dispose()
+	s.Wait()
+
+	assert.Equal(t, []string{"created a.txt", "modified a.txt", "removed a.txt", "removed keep.txt"}, events(), "FromDirectoryWatch Test Error!")
+}
@@ -0,0 +1,229 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type subjectKind uint
+
+const (
+	publishSubject  subjectKind = iota // emits items arrived after subscribed
+	behaviorSubject                    // emits the latest item and items arrived after subscribed
+	replaySubject                      // emits all buffered items and items arrived after subscribed
+	asyncSubject                       // emits the last item when completed
+)
+
+// A Subject is both an Observer and an Observable. Items pushed by OnNext are emitted to all its observers.
+// OnError pushes an error as an item like other Observables, and only OnCompleted terminates the Subject
+type Subject struct {
+	*Observable
+
+	mu        sync.Mutex
+	kind      subjectKind
+	observers map[*publishObserver]struct{}
+	completed bool
+	// items replayed to new observers
+	history []timedItem
+	size    int           // max items in history, 0 means no limit
+	window  time.Duration // max age of items in history, 0 means no limit
+	clock   Clock
+}
+
+type timedItem struct {
+	t    time.Time
+	item interface{}
+}
+
+var _ Observer = &Subject{}
+
+// NewPublishSubject creates a Subject that emits to an observer only items arrived after it subscribed
+func NewPublishSubject() *Subject {
+	return newSubject("PublishSubject", publishSubject)
+}
+
+// NewBehaviorSubject creates a Subject that emits the latest item (or initial if none) when an observer subscribes,
+// and then items arrived after it subscribed
+func NewBehaviorSubject(initial interface{}) *Subject {
+	s := newSubject("BehaviorSubject", behaviorSubject)
+	s.size = 1
+	s.record(initial)
+	return s
+}
+
+// NewReplaySubject creates a Subject that emits all buffered items when an observer subscribes.
+// The buffer holds at most size items not older than window, 0 means no limit
+func NewReplaySubject(size int, window time.Duration) *Subject {
+	s := newSubject("ReplaySubject", replaySubject)
+	s.size = size
+	s.window = window
+	return s
+}
+
+// NewAsyncSubject creates a Subject that emits only the last item to its observers when it completed
+func NewAsyncSubject() *Subject {
+	s := newSubject("AsyncSubject", asyncSubject)
+	s.size = 1
+	return s
+}
+
+// SetClock sets the Clock for time window of ReplaySubject, the default is DefaultClock
+func (s *Subject) SetClock(c Clock) *Subject {
+	s.mu.Lock()
+	s.clock = c
+	s.mu.Unlock()
+	return s
+}
+
+// Value returns the latest item of the Subject, ok is false if there is no item
+func (s *Subject) Value() (x interface{}, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) == 0 {
+		return nil, false
+	}
+	return s.history[len(s.history)-1].item, true
+}
+
+func (s *Subject) OnNext(x interface{}) {
+	s.mu.Lock()
+	if s.completed {
+		s.mu.Unlock()
+		return
+	}
+	if s.kind != publishSubject {
+		s.record(x)
+	}
+	if s.kind == asyncSubject {
+		s.mu.Unlock()
+		return
+	}
+	observers := s.snapshotObservers()
+	s.mu.Unlock()
+
+	for _, ob := range observers {
+		if !ob.push(x) {
+			s.remove(ob)
+		}
+	}
+}
+
+func (s *Subject) OnError(e error) {
+	s.OnNext(e)
+}
+
+func (s *Subject) OnCompleted() {
+	s.mu.Lock()
+	if s.completed {
+		s.mu.Unlock()
+		return
+	}
+	s.completed = true
+	observers := s.snapshotObservers()
+	s.observers = nil
+	last := s.replayItems()
+	s.mu.Unlock()
+
+	for _, ob := range observers {
+		if s.kind == asyncSubject && len(last) > 0 {
+			ob.push(last[0])
+		}
+		ob.close()
+	}
+}
+
+// register an observer when the Subject Observable is connected, so that it receives items pushed as soon as it subscribed.
+// It returns the function sending replayed and pushed items to the observer
+func (s *Subject) register(ctx context.Context) func(send func(x interface{}) (endSignal bool)) {
+	ob := newPublishObserver(ctx)
+
+	// items in history are either replayed or pushed after registered
+	s.mu.Lock()
+	completed := s.completed
+	var replay []interface{}
+	switch s.kind {
+	case replaySubject:
+		replay = s.replayItems()
+	case behaviorSubject:
+		if !completed {
+			replay = s.replayItems()
+		}
+	case asyncSubject:
+		if completed {
+			replay = s.replayItems()
+		}
+	}
+	if !completed {
+		s.observers[ob] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	return func(send func(x interface{}) (endSignal bool)) {
+		for _, x := range replay {
+			if send(x) {
+				s.remove(ob)
+				return
+			}
+		}
+		if !completed {
+			ob.drain(send)
+			s.remove(ob)
+		}
+	}
+}
+
+// append x to history, must hold s.mu
+func (s *Subject) record(x interface{}) {
+	s.history = append(s.history, timedItem{s.clock.Now(), x})
+	if s.size > 0 && len(s.history) > s.size {
+		s.history = s.history[len(s.history)-s.size:]
+	}
+}
+
+// items in history not older than window, must hold s.mu
+func (s *Subject) replayItems() []interface{} {
+	if s.window > 0 {
+		deadline := s.clock.Now().Add(-s.window)
+		i := 0
+		for i < len(s.history) && s.history[i].t.Before(deadline) {
+			i++
+		}
+		s.history = s.history[i:]
+	}
+	items := make([]interface{}, len(s.history))
+	for i, ti := range s.history {
+		items[i] = ti.item
+	}
+	return items
+}
+
+// must hold s.mu
+func (s *Subject) snapshotObservers() []*publishObserver {
+	observers := make([]*publishObserver, 0, len(s.observers))
+	for ob := range s.observers {
+		observers = append(observers, ob)
+	}
+	return observers
+}
+
+func (s *Subject) remove(ob *publishObserver) {
+	s.mu.Lock()
+	delete(s.observers, ob)
+	s.mu.Unlock()
+}
+
+func newSubject(name string, kind subjectKind) *Subject {
+	s := &Subject{
+		kind:      kind,
+		observers: make(map[*publishObserver]struct{}),
+		clock:     DefaultClock,
+	}
+	s.Observable = newGeneratorObservable(name)
+	s.operator = multicastOperater{s.register}
+	return s
+}
@@ -0,0 +1,156 @@
+package rxgo
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// subscribe the Subject, the observer is registered when SubscribeAsync returns
+func subscribeSubject(s *Subject, res *[]int) *Subscription {
+	return s.SubscribeAsync(func(x int) {
+		*res = append(*res, x)
+	})
+}
+
+func TestPublishSubject(t *testing.T) {
+	s := NewPublishSubject()
+	res1, res2 := []int{}, []int{}
+
+	s.OnNext(0)
+	sub1 := subscribeSubject(s, &res1)
+	s.OnNext(1)
+	s.OnNext(2)
+	sub2 := subscribeSubject(s, &res2)
+	s.OnNext(3)
+	s.OnCompleted()
+	sub1.Wait()
+	sub2.Wait()
+
+	assert.Equal(t, []int{1, 2, 3}, res1, "PublishSubject Test Error!")
+	assert.Equal(t, []int{3}, res2, "PublishSubject Test Error!")
+}
+
+func TestBehaviorSubject(t *testing.T) {
+	s := NewBehaviorSubject(0)
+	res1, res2, res3 := []int{}, []int{}, []int{}
+
+	sub1 := subscribeSubject(s, &res1)
+	s.OnNext(1)
+	s.OnNext(2)
+	sub2 := subscribeSubject(s, &res2)
+	s.OnNext(3)
+	v, ok := s.Value()
+	s.OnCompleted()
+	sub3 := subscribeSubject(s, &res3)
+	sub1.Wait()
+	sub2.Wait()
+	sub3.Wait()
+
+	assert.Equal(t, []int{0, 1, 2, 3}, res1, "BehaviorSubject Test Error!")
+	assert.Equal(t, []int{2, 3}, res2, "BehaviorSubject Test Error!")
+	assert.Equal(t, []int{}, res3, "BehaviorSubject completed Test Error!")
+	assert.True(t, ok, "BehaviorSubject has no value!")
+	assert.Equal(t, 3, v, "BehaviorSubject Value Test Error!")
+}
+
+func TestReplaySubject(t *testing.T) {
+	s := NewReplaySubject(3, 0)
+	Just(1, 2, 3, 4, 5).Subscribe(s)
+
+	res := []int{}
+	subscribeSubject(s, &res).Wait()
+	assert.Equal(t, []int{3, 4, 5}, res, "ReplaySubject Test Error!")
+}
+
+func TestReplaySubjectWindow(t *testing.T) {
+	ts := NewTestScheduler()
+	s := NewReplaySubject(0, 30*time.Millisecond).SetClock(ts)
+	s.OnNext(1)
+	ts.AdvanceBy(50 * time.Millisecond)
+	s.OnNext(2)
+
+	res := []int{}
+	sub := subscribeSubject(s, &res)
+	s.OnNext(3)
+	s.OnCompleted()
+	sub.Wait()
+	assert.Equal(t, []int{2, 3}, res, "ReplaySubject window Test Error!")
+}
+
+func TestAsyncSubject(t *testing.T) {
+	s := NewAsyncSubject()
+	res1, res2 := []int{}, []int{}
+
+	sub1 := subscribeSubject(s, &res1)
+	s.OnNext(1)
+	s.OnNext(2)
+	s.OnNext(3)
+	s.OnCompleted()
+	sub2 := subscribeSubject(s, &res2)
+	sub1.Wait()
+	sub2.Wait()
+
+	assert.Equal(t, []int{3}, res1, "AsyncSubject Test Error!")
+	assert.Equal(t, []int{3}, res2, "AsyncSubject Test Error!")
+}
+
+func TestSubjectPipeline(t *testing.T) {
+	s := NewPublishSubject()
+	res := []int{}
+	sub := s.Map(func(x int) int {
+		return x * 10
+	}).SubscribeAsync(func(x int) {
+		res = append(res, x)
+	})
+
+	// bridge a callback API into the pipeline
+	for i := 1; i <= 3; i++ {
+		s.OnNext(i)
+	}
+	s.OnCompleted()
+	sub.Wait()
+
+	assert.Equal(t, []int{10, 20, 30}, res, "Subject pipeline Test Error!")
+}
+
+func TestSubjectConcurrentCompleted(t *testing.T) {
+	// OnCompleted while OnNext is pushing to a slow observer must not send on the closed channel
+	s := NewPublishSubject()
+	release := make(chan struct{})
+	n := 0
+	sub := s.SubscribeAsync(func(x int) {
+		<-release
+		n++
+	})
+	var wg sync.WaitGroup
+	for j := 0; j < 4; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				s.OnNext(k)
+			}
+		}()
+	}
+	// wait for pushes blocked on the full channel of the observer
+	s.mu.Lock()
+	ob := s.snapshotObservers()[0]
+	s.mu.Unlock()
+	for len(ob.ch) < cap(ob.ch) {
+		runtime.Gosched()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.OnCompleted()
+	}()
+	close(release)
+	wg.Wait()
+	sub.Wait()
+
+	assert.True(t, n >= cap(ob.ch) && n <= 400, "Subject Concurrent Completed Test Error!")
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Disposable is a resource, such as a subscription, that can be released
+type Disposable interface {
+	Dispose()
+	IsDisposed() bool
+}
+
+var _ Disposable = &Subscription{}
+var _ Disposable = &CompositeDisposable{}
+
+// Subscription is the handle of connected Observables
+type Subscription struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	flows  *sync.WaitGroup // goroutines started by operators of this subscription
+	// flow resources of the subscriber
+	in        chan interface{}
+	observer  Observer
+	fv        reflect.Value
+	sched     Scheduler
+	dedicated bool
+	// stop delivering after the first error
+	terminate_on_error bool
+	// requested items of SubscribePull
+	demand *demand
+	// the last Observable and the Instrumentation of the subscriber context
+	last *Observable
+	inst Instrumentation
+}
+
+// Unsubscribe cancels the Observables, no more items will be delivered to the observer
+func (s *Subscription) Unsubscribe() {
+	s.cancel()
+}
+
+// Dispose is the same as Unsubscribe, it does not wait for the Observables closed
+func (s *Subscription) Dispose() {
+	s.cancel()
+}
+
+// IsDisposed reports whether the subscription is cancelled or completed
+func (s *Subscription) IsDisposed() bool {
+	return s.ctx.Err() != nil
+}
+
+// Done returns a channel that's closed when the subscription is completed or cancelled,
+// and all goroutines of its Observables exited
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Wait blocks until the subscription is completed or cancelled, and all goroutines of its Observables exited
+func (s *Subscription) Wait() {
+	<-s.done
+}
+
+// consume the last outflow and deliver items to the observer
+func (s *Subscription) run() {
+	defer close(s.done)
+	defer s.cancel()
+
+	var err error // the error terminated the subscription
+	for x := range s.in {
+		if s.ctx.Err() != nil {
+			continue // unsubscribed, waiting for the Observables closed
+		}
+		if s.demand != nil && !s.demand.acquire(s.ctx) {
+			continue
+		}
+		s.deliver(func() {
+			s.onNext(x)
+		})
+		if e, ok := x.(error); ok && s.terminate_on_error {
+			err = e
+			s.cancel()
+		}
+	}
+	if err == nil {
+		err = s.ctx.Err()
+	}
+	if s.observer != nil && s.ctx.Err() == nil {
+		s.deliver(s.observer.OnCompleted)
+	}
+	// operators like Take complete before their upstream, which is cancelled now
+	s.cancel()
+	s.flows.Wait()
+	if s.inst != nil {
+		s.inst.Unsubscribed(s.ctx, s.last, err)
+	}
+}
+
+func (s *Subscription) onNext(x interface{}) {
+	if s.observer != nil {
+		if e, ok := x.(error); ok {
+			s.observer.OnError(e)
+
+		} else {
+			s.observer.OnNext(x)
+		}
+	} else {
+		if _, ok := x.(error); ok {
+			// skip error
+		} else {
+			params := []reflect.Value{reflect.ValueOf(x)}
+			s.fv.Call(params)
+		}
+	}
+}
+
+// run task on the Scheduler and wait for it, so that observer receives items one by one
+func (s *Subscription) deliver(task func()) {
+	if s.sched == nil {
+		task()
+		return
+	}
+	done := make(chan struct{})
+	s.sched.Schedule(func() {
+		defer close(done)
+		task()
+	})
+	<-done
+}
+
+type flowGroupKey struct{}
+
+// start a goroutine of Observables, which is tracked by the subscription owning ctx
+func goFlow(ctx context.Context, f func()) {
+	wg, ok := ctx.Value(flowGroupKey{}).(*sync.WaitGroup)
+	if !ok {
+		go f()
+		return
+	}
+	wg.Add(1)
+	inst := InstrumentationOf(ctx)
+	if inst != nil {
+		inst.GoroutineStarted(ctx)
+	}
+	go func() {
+		defer wg.Done()
+		if inst != nil {
+			defer inst.GoroutineExited(ctx)
+		}
+		f()
+	}()
+}
+
+// CompositeDisposable disposes a group of Disposables at once
+type CompositeDisposable struct {
+	mu       sync.Mutex
+	disposed bool
+	items    []Disposable
+}
+
+// NewCompositeDisposable creates a CompositeDisposable holding ds
+func NewCompositeDisposable(ds ...Disposable) *CompositeDisposable {
+	c := &CompositeDisposable{}
+	c.Add(ds...)
+	return c
+}
+
+// Add puts ds into the group, they are disposed at once if the group has been disposed
+func (c *CompositeDisposable) Add(ds ...Disposable) {
+	c.mu.Lock()
+	if !c.disposed {
+		c.items = append(c.items, ds...)
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+	for _, d := range ds {
+		d.Dispose()
+	}
+}
+
+// Dispose disposes all Disposables in the group
+func (c *CompositeDisposable) Dispose() {
+	c.mu.Lock()
+	items := c.items
+	c.items, c.disposed = nil, true
+	c.mu.Unlock()
+	for _, d := range items {
+		d.Dispose()
+	}
+}
+
+func (c *CompositeDisposable) IsDisposed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.disposed
+}
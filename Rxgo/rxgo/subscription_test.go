@@ -0,0 +1,151 @@
+package rxgo
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeAsync(t *testing.T) {
+	ch := make(chan int)
+	res := []int{}
+	completed := false
+	s := From(ch).Map(func(x int) int {
+		return x * 2
+	}).SubscribeAsync(ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x.(int))
+		},
+		Completed: func() {
+			completed = true
+		},
+	})
+
+	// SubscribeAsync must not wait for the source
+	ch <- 1
+	ch <- 2
+	close(ch)
+	s.Wait()
+
+	assert.Equal(t, []int{2, 4}, res, "SubscribeAsync Test Error!")
+	assert.True(t, completed, "SubscribeAsync not completed!")
+}
+
+func TestSubscribeAsyncUnsubscribe(t *testing.T) {
+	completed := false
+	s := Never().SubscribeAsync(ObserverMonitor{
+		Completed: func() {
+			completed = true
+		},
+	})
+	s.Unsubscribe()
+
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Unsubscribe Test Error!")
+	}
+	assert.False(t, completed, "OnCompleted after Unsubscribe!")
+}
+
+func TestObserveOn(t *testing.T) {
+	for _, tm := range []ThreadModel{ThreadingDefault, ThreadingIO, ThreadingComputing} {
+		res := []int{}
+		Range(0, 5).Map(func(x int) int {
+			return x + 1
+		}).ObserveOn(tm).Subscribe(func(x int) {
+			res = append(res, x)
+		})
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, res, "ObserveOn Test Error!")
+	}
+}
+
+func TestObserveOnScheduler(t *testing.T) {
+	var tasks int32
+	executor := SchedulerFunc(func(task func()) {
+		atomic.AddInt32(&tasks, 1)
+		go task()
+	})
+
+	res := []int{}
+	Just(1, 2, 3).ObserveOnScheduler(executor).Subscribe(ObserverMonitor{
+		Next: func(x interface{}) {
+			res = append(res, x.(int))
+		},
+	})
+
+	assert.Equal(t, []int{1, 2, 3}, res, "ObserveOnScheduler Test Error!")
+	assert.Equal(t, int32(4), atomic.LoadInt32(&tasks), "3 items and completed should be scheduled")
+}
+
+// wait for goroutines of disposed subscriptions exited
+func assertNoLeak(t *testing.T, base int) {
+	t.Helper()
+	n := runtime.NumGoroutine()
+	for i := 0; i < 100 && n > base; i++ {
+		time.Sleep(10 * time.Millisecond)
+		n = runtime.NumGoroutine()
+	}
+	assert.True(t, n <= base, "goroutines leaked: %d > %d", n, base)
+}
+
+func TestDisposeNoLeak(t *testing.T) {
+	base := runtime.NumGoroutine()
+	s := Range(0, 1<<30).Map(func(x int) int {
+		return x + 1
+	}).SubscribeOn(ThreadingIO).Filter(func(x int) bool {
+		return x%2 == 0
+	}).SubscribeOn(ThreadingComputing).Debounce(time.Millisecond).SubscribeAsync(func(x int) {})
+
+	time.Sleep(20 * time.Millisecond)
+	s.Dispose()
+	s.Wait()
+
+	assert.True(t, s.IsDisposed(), "Dispose Test Error!")
+	assertNoLeak(t, base)
+}
+
+func TestDisposeCombiningNoLeak(t *testing.T) {
+	base := runtime.NumGoroutine()
+	s := Merge(Never(), Range(0, 1<<30)).FlatMap(func(x int) *Observable {
+		return Just(x, x)
+	}).SubscribeAsync(func(x int) {})
+	z := CombineLatest(func(x, y int) int {
+		return x + y
+	}, Never(), Range(0, 1<<30)).SubscribeAsync(func(x int) {})
+
+	time.Sleep(20 * time.Millisecond)
+	c := NewCompositeDisposable(s, z)
+	c.Dispose()
+	s.Wait()
+	z.Wait()
+
+	assert.True(t, c.IsDisposed(), "Composite Dispose Test Error!")
+	assertNoLeak(t, base)
+}
+
+func TestCompositeDisposable(t *testing.T) {
+	c := NewCompositeDisposable()
+	s1 := Never().SubscribeAsync(func(x int) {})
+	c.Add(s1)
+	assert.False(t, s1.IsDisposed(), "Composite disposed too early!")
+
+	c.Dispose()
+	s1.Wait()
+	assert.True(t, s1.IsDisposed(), "Composite Dispose Test Error!")
+
+	// added after disposed
+	s2 := Never().SubscribeAsync(func(x int) {})
+	c.Add(s2)
+	s2.Wait()
+	assert.True(t, s2.IsDisposed(), "Composite Add Test Error!")
+}
+
+func TestSubscriptionCompleted(t *testing.T) {
+	s := Just(1, 2, 3).SubscribeAsync(func(x int) {})
+	s.Wait()
+	assert.True(t, s.IsDisposed(), "completed subscription should be disposed")
+}
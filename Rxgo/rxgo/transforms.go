@@ -8,6 +8,7 @@ import (
 	"context"
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -30,43 +31,71 @@ func (tsop transOperater) op(ctx context.Context, o *Observable) {
 	out := o.outflow
 	//fmt.Println(o.name, "operator in/out chan ", in, out)
 	var wg sync.WaitGroup
+	sched := ComputingScheduler()
+	limit := o.inflightLimit(sched)
+	seq := newSequencer(ctx, o, out)
 
-	go func() {
-		end := false
+	goFlow(ctx, func() {
+		var end int32 // set by the goroutines of items
 		for x := range in {
-			if end {
+			if atomic.LoadInt32(&end) == 1 {
 				continue
 			}
 			// can not pass a interface as parameter (pointer) to gorountion for it may change its value outside!
 			xv := reflect.ValueOf(x)
 			// send an error to stream if the flip not accept error
 			if e, ok := x.(error); ok && !o.flip_accept_error {
-				o.sendToFlow(ctx, e, out)
+				ch := seq.slot(out)
+				if o.sendToFlow(ctx, e, ch) {
+					atomic.StoreInt32(&end, 1)
+				}
+				seq.complete(ch)
 				continue
 			}
 			// scheduler
 			switch threading := o.threading; threading {
 			case ThreadingDefault:
 				if tsop.opFunc(ctx, o, xv, out) {
-					end = true
+					atomic.StoreInt32(&end, 1)
 				}
 			case ThreadingIO:
-				fallthrough
-			case ThreadingComputing:
+				if !limit.acquire(ctx) {
+					atomic.StoreInt32(&end, 1)
+					continue
+				}
 				wg.Add(1)
+				ch := seq.slot(out)
 				go func() {
 					defer wg.Done()
-					if tsop.opFunc(ctx, o, xv, out) {
-						end = true
+					defer limit.release()
+					defer seq.complete(ch)
+					if tsop.opFunc(ctx, o, xv, ch) {
+						atomic.StoreInt32(&end, 1)
 					}
 				}()
+			case ThreadingComputing:
+				if !limit.acquire(ctx) {
+					atomic.StoreInt32(&end, 1)
+					continue
+				}
+				wg.Add(1)
+				ch := seq.slot(out)
+				scheduleComputing(ctx, sched, ch, func(ctx context.Context) {
+					defer wg.Done()
+					defer limit.release()
+					defer seq.complete(ch)
+					if tsop.opFunc(ctx, o, xv, ch) {
+						atomic.StoreInt32(&end, 1)
+					}
+				})
 			default:
 			}
 		}
 
 		wg.Wait() //waiting all go-routines completed
-		o.closeFlow(out)
-	}()
+		seq.wait()
+		o.closeFlow(ctx, out)
+	})
 }
 
 func (parent *Observable) TransformOp(tf transformFunc) (o *Observable) {
@@ -116,7 +145,6 @@ var mapOperater = transOperater{func(ctx context.Context, o *Observable, x refle
 	var params = []reflect.Value{x}
 	rs, skip, stop, e := userFuncCall(fv, params)
 
-	var item interface{} = rs[0].Interface()
 	if stop {
 		end = true
 		return
@@ -124,8 +152,12 @@ var mapOperater = transOperater{func(ctx context.Context, o *Observable, x refle
 	if skip {
 		return
 	}
+	// no result if user function throws an error
+	var item interface{}
 	if e != nil {
 		item = e
+	} else {
+		item = rs[0].Interface()
 	}
 	// send data
 	if !end {
@@ -137,6 +169,7 @@ var mapOperater = transOperater{func(ctx context.Context, o *Observable, x refle
 
 // FlatMap maps each item in Observable by the function with `func(x anytype) (o *Observable) ` and
 // returns a new Observable with merged observables appling on each items.
+// With ThreadingDefault the observables are drained one by one like ConcatMap, see MergeMap for concurrent ones.
 func (parent *Observable) FlatMap(f interface{}) (o *Observable) {
 	// check validation of f
 	fv := reflect.ValueOf(f)
@@ -156,49 +189,121 @@ func (parent *Observable) FlatMap(f interface{}) (o *Observable) {
 	return o
 }
 
-var flatMapOperater = transOperater{func(ctx context.Context, o *Observable, x reflect.Value, out chan interface{}) (end bool) {
+// FlatMap node. Like a transform node, functions of items run by the threading model of the Observable,
+// in the computing group with ThreadingComputing, but inner Observables are drained outside the group,
+// for tasks holding all slots and waiting for inner Observables that need slots themselves never end.
+// The drains are limited by the in-flight limit instead.
+// With ThreadingDefault each group of GroupBy is drained by its own goroutine, for groups emit items
+// at the same time and draining them one by one blocks GroupBy
+type flatTransOperater struct{}
+
+func (flatTransOperater) op(ctx context.Context, o *Observable) {
+	in := o.pred.outflow
+	out := o.outflow
+	var wg sync.WaitGroup
+	sched := ComputingScheduler()
+	limit := o.inflightLimit(sched)
+	seq := newSequencer(ctx, o, out)
+
+	goFlow(ctx, func() {
+		var end int32 // set by the goroutines of items
+		for x := range in {
+			if atomic.LoadInt32(&end) == 1 {
+				continue
+			}
+			xv := reflect.ValueOf(x)
+			if e, ok := x.(error); ok && !o.flip_accept_error {
+				ch := seq.slot(out)
+				if o.sendToFlow(ctx, e, ch) {
+					atomic.StoreInt32(&end, 1)
+				}
+				seq.complete(ch)
+				continue
+			}
+			switch threading := o.threading; threading {
+			case ThreadingDefault:
+				if _, ok := x.(*GroupedObservable); ok {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if o.flatMapItem(ctx, nil, xv, out) {
+							atomic.StoreInt32(&end, 1)
+						}
+					}()
+					continue
+				}
+				if o.flatMapItem(ctx, nil, xv, out) {
+					atomic.StoreInt32(&end, 1)
+				}
+			case ThreadingIO, ThreadingComputing:
+				var run Scheduler
+				if threading == ThreadingComputing {
+					run = sched
+				}
+				if !limit.acquire(ctx) {
+					atomic.StoreInt32(&end, 1)
+					continue
+				}
+				wg.Add(1)
+				ch := seq.slot(out)
+				go func() {
+					defer wg.Done()
+					defer limit.release()
+					defer seq.complete(ch)
+					if o.flatMapItem(ctx, run, xv, ch) {
+						atomic.StoreInt32(&end, 1)
+					}
+				}()
+			default:
+			}
+		}
+
+		wg.Wait()
+		seq.wait()
+		o.closeFlow(ctx, out)
+	})
+}
+
+var flatMapOperater = flatTransOperater{}
 
+// flatMapItem calls the function of FlatMap with x, on sched if it is not nil, and sends items of the Observable
+// it returns to out
+func (o *Observable) flatMapItem(ctx context.Context, sched Scheduler, x reflect.Value, out chan interface{}) (end bool) {
 	fv := reflect.ValueOf(o.flip)
 	var params = []reflect.Value{x}
-	//fmt.Println("x is ", x)
-	rs, skip, stop, e := userFuncCall(fv, params)
-
-	var item = rs[0].Interface().(*Observable)
+	var rs []reflect.Value
+	var skip, stop bool
+	var e error
+	call := func() {
+		rs, skip, stop, e = userFuncCall(fv, params)
+	}
+	if sched != nil {
+		runOn(sched, call)
+	} else {
+		call()
+	}
 
 	if stop {
-		end = true
-		return
+		return true
 	}
 	if skip {
 		return
 	}
 	if e != nil {
-		end = o.sendToFlow(ctx, e, out)
-		if end {
-			return
-		}
+		return o.sendToFlow(ctx, e, out)
+	}
+	var item = rs[0].Interface().(*Observable)
+	if item == nil {
 		return
 	}
-	// send data
-	if !end {
-		if item != nil {
-			// subscribe ro without any ObserveOn model
-			ro := item
-			for ; ro.next != nil; ro = ro.next {
-			}
-			ro.connect(ctx)
-
-			ch := ro.outflow
-			for x := range ch {
-				end = o.sendToFlow(ctx, x, out)
-				if end {
-					return
-				}
-			}
+	// subscribe item without any ObserveOn model
+	for x := range item.connectTail(ctx) {
+		if o.sendToFlow(ctx, x, out) {
+			return true
 		}
 	}
 	return
-}}
+}
 
 // Filter `func(x anytype) bool` filters items in the original Observable and returns
 // a new Observable with the filtered items.
@@ -227,7 +332,6 @@ var filterOperater = transOperater{func(ctx context.Context, o *Observable, x re
 	var params = []reflect.Value{x}
 	rs, skip, stop, e := userFuncCall(fv, params)
 
-	var item interface{} = rs[0].Interface()
 	if stop {
 		end = true
 		return
@@ -235,8 +339,12 @@ var filterOperater = transOperater{func(ctx context.Context, o *Observable, x re
 	if skip {
 		return
 	}
+	// no result if user function throws an error
+	var item interface{}
 	if e != nil {
 		item = e
+	} else {
+		item = rs[0].Interface()
 	}
 	// send data
 	if !end {
@@ -253,12 +361,11 @@ func (parent *Observable) newTransformObservable(name string) (o *Observable) {
 	o = newObservable()
 	o.Name = name
 
-	//chain Observables
-	parent.next = o
+	//chain Observables, the parent is never changed
 	o.pred = parent
-	o.root = parent.root
 
 	//set options
 	o.buf_len = BufferLen
+	o.error_policy = parent.error_policy
 	return o
 }
@@ -0,0 +1,35 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package typed
+
+import (
+	"iter"
+
+	"gitee.com/li-jia666/rxgo"
+)
+
+// Iterate returns an iterator subscribing ob like rxgo Iterate, which yields items with nil errors and errors with zero values.
+// An item not of type T is yielded as FlowableError with ErrItemType
+func (ob *Observable[T]) Iterate() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, e := range ob.o.Iterate() {
+			if !yield(typedItem[T](item, e)) {
+				return
+			}
+		}
+	}
+}
+
+// FromSeq creates an Observable that emits values of the iterator
+func FromSeq[T any](seq iter.Seq[T]) *Observable[T] {
+	return &Observable[T]{rxgo.FromSeq(seq)}
+}
+
+// FromIter creates an Observable that emits values of the iterator, and sends its non-nil errors to stream
+func FromIter[T any](seq iter.Seq2[T, error]) *Observable[T] {
+	return &Observable[T]{rxgo.FromIter(seq)}
+}
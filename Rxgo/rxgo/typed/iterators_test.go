@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package typed
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"gitee.com/li-jia666/rxgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterate(t *testing.T) {
+	var res []string
+	for x, e := range Map(FromSeq(slices.Values([]int{1, 2, 3})), func(x int) string {
+		return string(rune('a' + x))
+	}).Iterate() {
+		assert.NoError(t, e, "Iterate Test Error!")
+		res = append(res, x)
+	}
+	assert.Equal(t, []string{"b", "c", "d"}, res, "Iterate Test Error!")
+
+	// an item of another type
+	for _, e := range (&Observable[int]{rxgo.Just("x")}).Iterate() {
+		assert.Equal(t, ErrItemType, e.(rxgo.FlowableError).Err, "Iterate Test Error!")
+	}
+}
+
+func TestFromIter(t *testing.T) {
+	ee := errors.New("Any")
+	seq := func(yield func(int, error) bool) {
+		_ = yield(1, nil) && yield(0, ee) && yield(2, nil)
+	}
+	var errs []error
+	res := []int{}
+	for x, e := range FromIter(seq).Iterate() {
+		if e != nil {
+			errs = append(errs, e)
+			continue
+		}
+		res = append(res, x)
+	}
+	assert.Equal(t, []int{1, 2}, res, "FromIter Test Error!")
+	assert.Equal(t, []error{ee}, errs, "FromIter Test Error!")
+}
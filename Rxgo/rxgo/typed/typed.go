@@ -0,0 +1,271 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package typed provides a type-checked API on top of rxgo, which requires Go 1.18 or later.
+// Pipelines are checked by the compiler, and user functions are called directly instead of by reflection.
+// A typed Observable is a view of a *rxgo.Observable, so both APIs can be mixed in one chain
+package typed
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gitee.com/li-jia666/rxgo"
+)
+
+// ErrItemType is the error of an item that is not of the type of the typed Observable
+var ErrItemType = errors.New("Item is not of the type!")
+
+// An Observable emits items of type T, and errors like rxgo Observables
+type Observable[T any] struct {
+	o *rxgo.Observable
+}
+
+// ObserverMonitor is the typed rxgo.ObserverMonitor
+type ObserverMonitor[T any] struct {
+	Next      func(x T)
+	Error     func(error)
+	Completed func()
+	Context   func() context.Context
+}
+
+// From views the rxgo Observable as Observable[T], an item not of type T is sent as FlowableError with ErrItemType
+func From[T any](o *rxgo.Observable) *Observable[T] {
+	o = o.TransformOp(func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool)) {
+		if _, ok := item.(error); ok {
+			send(item)
+		} else if _, ok := cast[T](item); ok {
+			send(item)
+		} else {
+			send(rxgo.FlowableError{Err: ErrItemType, Elements: item})
+		}
+	})
+	o.Name = "typed"
+	return &Observable[T]{o}
+}
+
+// Untyped returns the rxgo Observable of ob, to which rxgo operators can be chained
+func (ob *Observable[T]) Untyped() *rxgo.Observable {
+	return ob.o
+}
+
+// Just creates an Observable with the provided items
+func Just[T any](items ...T) *Observable[T] {
+	return FromSlice(items)
+}
+
+// FromSlice creates an Observable with items of the slice
+func FromSlice[T any](items []T) *Observable[T] {
+	o := rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for _, x := range items {
+			if send(x) {
+				return
+			}
+		}
+	})
+	o.Name = "FromSlice"
+	return &Observable[T]{o}
+}
+
+// Range creates an Observable that emits integers in [start, end)
+func Range(start, end int) *Observable[int] {
+	o := rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		for i := start; i < end; i++ {
+			if send(i) {
+				return
+			}
+		}
+	})
+	o.Name = "Range"
+	return &Observable[int]{o}
+}
+
+// Map maps each item by f
+func Map[T, U any](ob *Observable[T], f func(x T) U) *Observable[U] {
+	o := ob.o.TransformOp(func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool)) {
+		if x, ok := value[T](item); ok {
+			send(f(x))
+		} else {
+			send(item)
+		}
+	})
+	o.Name = "map"
+	return &Observable[U]{o}
+}
+
+// Filter emits only items that f returns true
+func Filter[T any](ob *Observable[T], f func(x T) bool) *Observable[T] {
+	o := ob.o.TransformOp(func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool)) {
+		if x, ok := value[T](item); !ok || f(x) {
+			send(item)
+		}
+	})
+	o.Name = "filter"
+	return &Observable[T]{o}
+}
+
+// FlatMap maps each item to an Observable by f, and emits items of these Observables
+func FlatMap[T, U any](ob *Observable[T], f func(x T) *Observable[U]) *Observable[U] {
+	o := ob.o.TransformOp(func(ctx context.Context, item interface{}, send func(x interface{}) (endSignal bool)) {
+		x, ok := value[T](item)
+		if !ok {
+			send(item)
+			return
+		}
+		inner := f(x)
+		if inner == nil {
+			return
+		}
+		ictx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		forward := func(x interface{}) {
+			if send(x) {
+				cancel()
+			}
+		}
+		inner.o.SubscribeAsync(rxgo.ObserverMonitor{
+			Next:  forward,
+			Error: func(e error) { forward(e) },
+			Context: func() context.Context {
+				return ictx
+			},
+		}).Wait()
+	})
+	o.Name = "flatMap"
+	return &Observable[U]{o}
+}
+
+// Reduce applies f to each item with the accumulation from seed, and emits the final accumulation
+func Reduce[T, A any](ob *Observable[T], seed A, f func(acc A, x T) A) *Observable[A] {
+	o := rxgo.Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		acc := seed
+		ictx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		end := false
+		ob.o.SubscribeAsync(rxgo.ObserverMonitor{
+			Next: func(item interface{}) {
+				if x, ok := value[T](item); ok {
+					acc = f(acc, x)
+				}
+			},
+			Error: func(e error) {
+				if send(e) {
+					end = true
+					cancel()
+				}
+			},
+			Context: func() context.Context {
+				return ictx
+			},
+		}).Wait()
+		if !end && ctx.Err() == nil {
+			send(acc)
+		}
+	})
+	o.Name = "reduce"
+	return &Observable[A]{o}
+}
+
+// Subscribe subscribes ob with a function receiving items, and blocks until ob completed
+func (ob *Observable[T]) Subscribe(next func(x T)) {
+	ob.SubscribeWith(ObserverMonitor[T]{Next: next})
+}
+
+// SubscribeWith subscribes ob with the observer like rxgo Subscribe
+func (ob *Observable[T]) SubscribeWith(m ObserverMonitor[T]) {
+	ob.o.Subscribe(m.untyped())
+}
+
+// SubscribeAsync subscribes ob with the observer like rxgo SubscribeAsync
+func (ob *Observable[T]) SubscribeAsync(m ObserverMonitor[T]) *rxgo.Subscription {
+	return ob.o.SubscribeAsync(m.untyped())
+}
+
+// ToSlice subscribes ob and returns all its items, it stops at the first error like rxgo BlockingSlice
+func (ob *Observable[T]) ToSlice() ([]T, error) {
+	items, e := ob.o.BlockingSlice()
+	res := make([]T, 0, len(items))
+	for _, item := range items {
+		if x, ok := cast[T](item); ok {
+			res = append(res, x)
+		}
+	}
+	return res, e
+}
+
+// Item is an item of type T or an error of an Observable, received from ToChannel
+type Item[T any] struct {
+	Value T
+	Err   error
+}
+
+// IsError reports whether the Item is an error
+func (it Item[T]) IsError() bool {
+	return it.Err != nil
+}
+
+// ToChannel subscribes ob like rxgo ToChannel, and returns a channel of its items and errors.
+// An item not of type T is received as FlowableError with ErrItemType
+func (ob *Observable[T]) ToChannel(ctx context.Context) <-chan Item[T] {
+	in := ob.o.ToChannel(ctx)
+	ch := make(chan Item[T])
+	go func() {
+		defer close(ch)
+		for it := range in {
+			x, e := typedItem[T](it.Value, it.Err)
+			select {
+			case ch <- Item[T]{Value: x, Err: e}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return ch
+}
+
+func (m ObserverMonitor[T]) untyped() rxgo.ObserverMonitor {
+	return rxgo.ObserverMonitor{
+		Next: func(item interface{}) {
+			if x, ok := cast[T](item); ok && m.Next != nil {
+				m.Next(x)
+			}
+		},
+		Error:     m.Error,
+		Completed: m.Completed,
+		Context:   m.Context,
+	}
+}
+
+// item as a value of type T. Errors are sent to stream instead of user functions, even if T is an interface type such as any
+func value[T any](item interface{}) (x T, ok bool) {
+	if _, isErr := item.(error); isErr {
+		return x, false
+	}
+	return cast[T](item)
+}
+
+// item and error received from rxgo as type T, an item not of type T is the error FlowableError with ErrItemType
+func typedItem[T any](item interface{}, e error) (x T, err error) {
+	if e != nil {
+		return x, e
+	}
+	x, ok := cast[T](item)
+	if !ok {
+		return x, rxgo.FlowableError{Err: ErrItemType, Elements: item}
+	}
+	return x, nil
+}
+
+// item as type T, a nil item is the nil value of interface, pointer, map, slice, func and chan types
+func cast[T any](item interface{}) (x T, ok bool) {
+	if item == nil {
+		switch reflect.TypeOf(&x).Elem().Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return x, true
+		}
+		return x, false
+	}
+	x, ok = item.(T)
+	return
+}
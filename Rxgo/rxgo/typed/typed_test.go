@@ -0,0 +1,147 @@
+package typed
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"gitee.com/li-jia666/rxgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapFilter(t *testing.T) {
+	res := []string{}
+	evens := Filter(Range(0, 10), func(x int) bool {
+		return x%2 == 0
+	})
+	Map(evens, strconv.Itoa).Subscribe(func(s string) {
+		res = append(res, s)
+	})
+
+	assert.Equal(t, []string{"0", "2", "4", "6", "8"}, res, "Typed Map and Filter Test Error!")
+}
+
+func TestMapAnyError(t *testing.T) {
+	// errors are not items even if T is an interface type
+	ee := errors.New("Any")
+	calls := 0
+	var errs []error
+	Map(From[any](rxgo.Throw(ee)), func(x any) any {
+		calls++
+		return x
+	}).SubscribeWith(ObserverMonitor[any]{
+		Error: func(e error) {
+			errs = append(errs, e)
+		},
+	})
+
+	assert.Equal(t, 0, calls, "Typed Map[any] Test Error!")
+	assert.Equal(t, []error{ee}, errs, "Typed Map[any] error Test Error!")
+
+	res, e := Filter(From[error](rxgo.Just(ee)), func(x error) bool { return false }).ToSlice()
+	assert.Len(t, res, 0, "Typed Filter[error] Test Error!")
+	assert.Equal(t, ee, e, "Typed Filter[error] Test Error!")
+}
+
+func TestFlatMap(t *testing.T) {
+	res, e := FlatMap(Just(1, 2, 3), func(x int) *Observable[int] {
+		return Just(x, x*10)
+	}).ToSlice()
+
+	assert.NoError(t, e)
+	assert.Equal(t, []int{1, 10, 2, 20, 3, 30}, res, "Typed FlatMap Test Error!")
+
+	first, _ := FlatMap(Range(0, 1000), func(x int) *Observable[int] {
+		return Range(0, 1000)
+	}).Untyped().BlockingFirst()
+	assert.Equal(t, 0, first, "Typed FlatMap cancel Test Error!")
+}
+
+func TestReduce(t *testing.T) {
+	res, e := Reduce(Range(1, 5), "", func(acc string, x int) string {
+		return acc + strconv.Itoa(x)
+	}).ToSlice()
+
+	assert.NoError(t, e)
+	assert.Equal(t, []string{"1234"}, res, "Typed Reduce Test Error!")
+}
+
+func TestInterop(t *testing.T) {
+	ee := errors.New("Any")
+	var errs []error
+	res := []int{}
+	From[int](rxgo.Just(1, "a", ee, 2)).SubscribeWith(ObserverMonitor[int]{
+		Next: func(x int) {
+			res = append(res, x)
+		},
+		Error: func(e error) {
+			errs = append(errs, e)
+		},
+	})
+
+	assert.Equal(t, []int{1, 2}, res, "Typed From Test Error!")
+	assert.Equal(t, []error{rxgo.FlowableError{Err: ErrItemType, Elements: "a"}, ee}, errs, "Typed From error Test Error!")
+
+	// rxgo operators on a typed chain
+	items, _ := Map(Range(0, 5), func(x int) int {
+		return x * x
+	}).Untyped().Skip(2).BlockingSlice()
+	assert.Equal(t, []interface{}{4, 9, 16}, items, "Typed Untyped Test Error!")
+
+	var ptrs []*int
+	Just[*int](nil).Subscribe(func(p *int) {
+		ptrs = append(ptrs, p)
+	})
+	assert.Equal(t, []*int{nil}, ptrs, "Typed nil item Test Error!")
+}
+
+func TestToChannel(t *testing.T) {
+	ee := errors.New("Any")
+	var items []Item[int]
+	for it := range (&Observable[int]{rxgo.Just(1, ee, "a", 3)}).ToChannel(context.Background()) {
+		items = append(items, it)
+	}
+	assert.Equal(t, []Item[int]{
+		{Value: 1},
+		{Err: ee},
+		{Err: rxgo.FlowableError{Err: ErrItemType, Elements: "a"}},
+		{Value: 3},
+	}, items, "Typed ToChannel Test Error!")
+	assert.True(t, items[1].IsError(), "Typed ToChannel Test Error!")
+
+	// cancelled before the end
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	for it := range Range(0, 1<<30).ToChannel(ctx) {
+		assert.Equal(t, n, it.Value, "Typed ToChannel Test Error!")
+		if n++; n == 3 {
+			cancel()
+		}
+	}
+	assert.True(t, n >= 3, "Typed ToChannel cancel Test Error!")
+}
+
+const benchItems = 10000
+
+func BenchmarkReflectMapFilter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rxgo.Range(0, benchItems).Map(func(x int) int {
+			return x * 2
+		}).Filter(func(x int) bool {
+			return x%3 == 0
+		}).Subscribe(func(x int) {})
+	}
+}
+
+func BenchmarkTypedMapFilter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		doubled := Map(Range(0, benchItems), func(x int) int {
+			return x * 2
+		})
+		Filter(doubled, func(x int) bool {
+			return x%3 == 0
+		}).Subscribe(func(x int) {})
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DoOnNext calls the function with `func(x anytype)` for each item, and emits the item.
+// An error thrown by the function is sent to stream instead of the item
+func (parent *Observable) DoOnNext(f interface{}) (o *Observable) {
+	// check validation of f
+	fv := reflect.ValueOf(f)
+	inType := []reflect.Type{typeAny}
+	b, ctx_sup := checkFuncUpcast(fv, inType, []reflect.Type{}, true)
+	if !b {
+		panic(ErrFuncFlip)
+	}
+
+	o = parent.newFilterObservable("doOnNext", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			params := []reflect.Value{reflect.ValueOf(x)}
+			if o.flip_sup_ctx {
+				params = append([]reflect.Value{reflect.ValueOf(ctx)}, params...)
+			}
+			_, skip, stop, e := userFuncCall(reflect.ValueOf(o.flip), params)
+			switch {
+			case stop:
+				return true
+			case skip:
+				return false
+			case e != nil:
+				return o.sendToFlow(ctx, e, out)
+			}
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
+	o.flip_sup_ctx = ctx_sup
+	o.flip = fv.Interface()
+	return o
+}
+
+// DoOnError calls f for each error, and emits the error
+func (parent *Observable) DoOnError(f func(e error)) (o *Observable) {
+	o = parent.newFilterObservable("doOnError", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			if e, ok := x.(error); ok {
+				f(e)
+			}
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
+	o.flip_accept_error = true
+	return o
+}
+
+// DoOnCompleted calls f when the Observable completed. It is not called if the stream is cancelled or terminated by an error
+func (parent *Observable) DoOnCompleted(f func()) (o *Observable) {
+	return parent.newFilterObservable("doOnCompleted", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				return o.sendToFlow(ctx, x, out)
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				f()
+			},
+		}
+	})
+}
+
+// DoOnSubscribe calls f each time the Observable is connected by a subscription
+func (parent *Observable) DoOnSubscribe(f func()) (o *Observable) {
+	return parent.newFilterObservable("doOnSubscribe", func(ctx context.Context, o *Observable) *filter {
+		f()
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			return o.sendToFlow(ctx, x, out)
+		}}
+	})
+}
+
+// DoFinally calls f after the Observable closed, whether it is completed, terminated by an error or cancelled
+func (parent *Observable) DoFinally(f func()) (o *Observable) {
+	return parent.newFilterObservable("doFinally", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				return o.sendToFlow(ctx, x, out)
+			},
+			stop: f,
+		}
+	})
+}
+
+// Delay shifts each item forward in time by d. Errors are not delayed,
+// and the Observable completes after the last item emitted
+func (parent *Observable) Delay(d time.Duration) (o *Observable) {
+	o = parent.newFilterObservable("delay", func(ctx context.Context, o *Observable) *filter {
+		type delayed struct {
+			due time.Time
+			x   interface{}
+		}
+		clock := ClockOf(ctx)
+		var queue []delayed
+		timer := clock.NewTimer(d)
+		timer.Stop()
+		// emit items that are due, and wait for the next one
+		emit := func(ctx context.Context, out chan interface{}) (end bool) {
+			now := clock.Now()
+			for len(queue) > 0 && !queue[0].due.After(now) {
+				x := queue[0].x
+				queue = queue[1:]
+				if o.sendToFlow(ctx, x, out) {
+					return true
+				}
+			}
+			if len(queue) > 0 {
+				timer.Reset(queue[0].due.Sub(now))
+			}
+			return
+		}
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				queue = append(queue, delayed{clock.Now().Add(d), x})
+				if len(queue) == 1 {
+					timer.Reset(d)
+				}
+				return
+			},
+			ticks: timer.C(),
+			tick:  emit,
+			complete: func(ctx context.Context, out chan interface{}) {
+				for len(queue) > 0 {
+					select {
+					case <-timer.C():
+					case <-ctx.Done():
+						return
+					}
+					if emit(ctx, out) {
+						return
+					}
+				}
+			},
+			stop: func() { timer.Stop() },
+		}
+	})
+	o.timespan = d
+	return o
+}
+
+// Timeout emits items until no item arrives in d after the subscription or the previous item.
+// Then it continues with the fallback Observable, or sends ErrTimeout and completes if fallback is nil
+func (parent *Observable) Timeout(d time.Duration, fallback *Observable) (o *Observable) {
+	o = parent.newFilterObservable("timeout", func(ctx context.Context, o *Observable) *filter {
+		timer := ClockOf(ctx).NewTimer(d)
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				timer.Reset(d)
+				return o.sendToFlow(ctx, x, out)
+			},
+			ticks: timer.C(),
+			tick: func(ctx context.Context, out chan interface{}) (end bool) {
+				// the upstream is never read after switched
+				timer.Stop()
+				cancelUpstream(ctx)
+				if len(o.sources) == 0 {
+					o.sendToFlow(ctx, ErrTimeout, out)
+					return true
+				}
+				o.forwardAll(ctx, o.sources[0], out)
+				return true
+			},
+			stop: func() { timer.Stop() },
+		}
+	})
+	if fallback != nil {
+		o.sources = []*Observable{fallback}
+	}
+	o.timespan = d
+	return o
+}
+
+// Timed is an item with its time, emitted by Timestamp and TimeInterval
+type Timed struct {
+	Value    interface{}
+	Time     time.Time     // when the item arrived
+	Interval time.Duration // time since the previous item, or the subscription for the first one
+}
+
+// Timestamp emits each item as a Timed with the time it arrived
+func (parent *Observable) Timestamp() (o *Observable) {
+	return parent.newFilterObservable("timestamp", func(ctx context.Context, o *Observable) *filter {
+		clock := ClockOf(ctx)
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			return o.sendToFlow(ctx, Timed{Value: x, Time: clock.Now()}, out)
+		}}
+	})
+}
+
+// TimeInterval emits each item as a Timed with the time elapsed since the previous item
+func (parent *Observable) TimeInterval() (o *Observable) {
+	return parent.newFilterObservable("timeInterval", func(ctx context.Context, o *Observable) *filter {
+		clock := ClockOf(ctx)
+		last := clock.Now()
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			now := clock.Now()
+			interval := now.Sub(last)
+			last = now
+			return o.sendToFlow(ctx, Timed{Value: x, Time: now, Interval: interval}, out)
+		}}
+	})
+}
+
+// NotificationKind is the kind of a Notification
+type NotificationKind uint
+
+const (
+	OnNextNotification      NotificationKind = iota // an item
+	OnErrorNotification                             // an error
+	OnCompletedNotification                         // the completion
+)
+
+// Notification is an event of an Observable as an item, emitted by Materialize
+type Notification struct {
+	Kind  NotificationKind
+	Value interface{} // item of OnNextNotification
+	Err   error       // error of OnErrorNotification
+}
+
+// Accept calls the method of the observer matching the kind
+func (n Notification) Accept(ob Observer) {
+	switch n.Kind {
+	case OnNextNotification:
+		ob.OnNext(n.Value)
+	case OnErrorNotification:
+		ob.OnError(n.Err)
+	case OnCompletedNotification:
+		ob.OnCompleted()
+	}
+}
+
+func (n Notification) String() string {
+	switch n.Kind {
+	case OnNextNotification:
+		return fmt.Sprint("OnNext(", n.Value, ")")
+	case OnErrorNotification:
+		return fmt.Sprint("OnError(", n.Err, ")")
+	default:
+		return "OnCompleted()"
+	}
+}
+
+// Materialize emits items and errors as Notifications, and then a Notification of the completion
+func (parent *Observable) Materialize() (o *Observable) {
+	o = parent.newFilterObservable("materialize", func(ctx context.Context, o *Observable) *filter {
+		return &filter{
+			next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+				if e, ok := x.(error); ok {
+					return o.sendToFlow(ctx, Notification{Kind: OnErrorNotification, Err: e}, out)
+				}
+				return o.sendToFlow(ctx, Notification{Kind: OnNextNotification, Value: x}, out)
+			},
+			complete: func(ctx context.Context, out chan interface{}) {
+				o.sendToFlow(ctx, Notification{Kind: OnCompletedNotification}, out)
+			},
+		}
+	})
+	o.flip_accept_error = true
+	return o
+}
+
+// Dematerialize turns Notifications back into items and errors, and completes on a Notification of the completion.
+// Other items are emitted as they are
+func (parent *Observable) Dematerialize() (o *Observable) {
+	return parent.newFilterObservable("dematerialize", func(ctx context.Context, o *Observable) *filter {
+		return &filter{next: func(ctx context.Context, x interface{}, out chan interface{}) (end bool) {
+			n, ok := x.(Notification)
+			if !ok {
+				return o.sendToFlow(ctx, x, out)
+			}
+			switch n.Kind {
+			case OnNextNotification:
+				return o.sendToFlow(ctx, n.Value, out)
+			case OnErrorNotification:
+				return o.sendToFlow(ctx, n.Err, out)
+			}
+			return true
+		}}
+	})
+}
@@ -0,0 +1,158 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	ee := errors.New("Any")
+	var nexts []interface{}
+	var errs []error
+	subscribed, completed, finally := 0, false, false
+	res, _ := collectAll(Just(1, ee, 3).DoOnNext(func(x int) {
+		nexts = append(nexts, x)
+	}).DoOnError(func(e error) {
+		errs = append(errs, e)
+	}).DoOnSubscribe(func() {
+		subscribed++
+	}).DoOnCompleted(func() {
+		completed = true
+	}).DoFinally(func() {
+		finally = true
+	}))
+
+	assert.Equal(t, []interface{}{1, ee, 3}, res, "Do Test Error!")
+	assert.Equal(t, []interface{}{1, 3}, nexts, "DoOnNext Test Error!")
+	assert.Equal(t, []error{ee}, errs, "DoOnError Test Error!")
+	assert.Equal(t, 1, subscribed, "DoOnSubscribe Test Error!")
+	assert.True(t, completed, "DoOnCompleted Test Error!")
+	assert.True(t, finally, "DoFinally Test Error!")
+
+	// DoFinally is called when the stream is cancelled
+	var sent int64
+	finally = false
+	res, _ = collectAll(countingSource(&sent).DoFinally(func() {
+		finally = true
+	}).Take(2))
+	assert.Equal(t, []interface{}{0, 1}, res, "DoFinally Test Error!")
+	assert.True(t, finally, "DoFinally Test Error!")
+}
+
+func TestDelay(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(timedSource(map[time.Duration]interface{}{
+		0:                    "a",
+		5 * time.Millisecond: "b",
+	}, 10*time.Millisecond).Delay(20*time.Millisecond), ts)
+
+	ts.AdvanceBy(19 * time.Millisecond)
+	assert.Len(t, v.items(), 0, "Delay emits too early!")
+	ts.AdvanceBy(time.Millisecond)
+	assert.Equal(t, []interface{}{"a"}, v.items(), "Delay Test Error!")
+	ts.AdvanceBy(5 * time.Millisecond)
+	assert.Equal(t, []interface{}{"a", "b"}, v.items(), "Delay Test Error!")
+	s.Wait()
+}
+
+func TestTimeout(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(timedSource(map[time.Duration]interface{}{
+		0:                     1,
+		5 * time.Millisecond:  2,
+		30 * time.Millisecond: 3,
+	}, 40*time.Millisecond).Timeout(10*time.Millisecond, Just("fallback")), ts)
+
+	ts.AdvanceBy(50 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{1, 2, "fallback"}, v.items(), "Timeout Test Error!")
+
+	// no fallback
+	res, completed := collectAll(Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		<-ctx.Done()
+	}).Timeout(10*time.Millisecond, nil))
+	assert.Equal(t, []interface{}{ErrTimeout}, res, "Timeout Test Error!")
+	assert.True(t, completed, "Timeout Test Error!")
+}
+
+func TestTimeoutCancelsUpstream(t *testing.T) {
+	ts := NewTestScheduler()
+	stopped := make(chan struct{})
+	source := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		defer close(stopped)
+		if !send(1) {
+			<-ctx.Done()
+		}
+	})
+	never := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		<-ctx.Done()
+	})
+	var v virtualObserver
+	s := v.subscribe(source.Timeout(10*time.Millisecond, never), ts)
+
+	// the upstream is cancelled while the fallback is running
+	ts.AdvanceBy(10 * time.Millisecond)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout does not cancel upstream!")
+	}
+	assert.Equal(t, []interface{}{1}, v.items(), "Timeout Test Error!")
+	s.Dispose()
+	s.Wait()
+}
+
+func TestTimestampAndTimeInterval(t *testing.T) {
+	source := func() *Observable {
+		return timedSource(map[time.Duration]interface{}{
+			5 * time.Millisecond:  "a",
+			15 * time.Millisecond: "b",
+		}, 20*time.Millisecond)
+	}
+	start := time.Unix(0, 0)
+
+	ts := NewTestScheduler()
+	var v virtualObserver
+	s := v.subscribe(source().Timestamp(), ts)
+	ts.AdvanceBy(30 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{
+		Timed{Value: "a", Time: start.Add(5 * time.Millisecond)},
+		Timed{Value: "b", Time: start.Add(15 * time.Millisecond)},
+	}, v.items(), "Timestamp Test Error!")
+
+	ts = NewTestScheduler()
+	v = virtualObserver{}
+	s = v.subscribe(source().TimeInterval(), ts)
+	ts.AdvanceBy(30 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{
+		Timed{Value: "a", Time: start.Add(5 * time.Millisecond), Interval: 5 * time.Millisecond},
+		Timed{Value: "b", Time: start.Add(15 * time.Millisecond), Interval: 10 * time.Millisecond},
+	}, v.items(), "TimeInterval Test Error!")
+}
+
+func TestMaterialize(t *testing.T) {
+	ee := errors.New("Any")
+	res, _ := collectAll(Just(1, ee).Materialize())
+	assert.Equal(t, []interface{}{
+		Notification{Kind: OnNextNotification, Value: 1},
+		Notification{Kind: OnErrorNotification, Err: ee},
+		Notification{Kind: OnCompletedNotification},
+	}, res, "Materialize Test Error!")
+	assert.Equal(t, "OnNext(1)", res[0].(Notification).String(), "Notification Test Error!")
+
+	res, completed := collectAll(Just(1, ee, 3).Materialize().Dematerialize())
+	assert.Equal(t, []interface{}{1, ee, 3}, res, "Dematerialize Test Error!")
+	assert.True(t, completed, "Dematerialize Test Error!")
+
+	// completes on the Notification of the completion
+	res, _ = collectAll(Just(Notification{Kind: OnNextNotification, Value: 1}, Notification{Kind: OnCompletedNotification}, 2).Dematerialize())
+	assert.Equal(t, []interface{}{1}, res, "Dematerialize Test Error!")
+}
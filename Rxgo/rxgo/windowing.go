@@ -0,0 +1,174 @@
+// Copyright 2018 The SS.SYSU Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rxgo
+
+import (
+	"context"
+	"time"
+)
+
+// how items are grouped into buffers or windows
+type bufferSpec struct {
+	count    int           // items of a chunk, 0 means no limit
+	skip     int           // a new chunk starts every skip items, only for count based chunks
+	timespan time.Duration // a chunk is closed after timespan, 0 means no limit
+}
+
+// a buffer or window under filling
+type chunk struct {
+	items  []interface{}
+	n      int
+	window *Subject
+}
+
+// buffer node implementation of streamOperator.
+// One goroutine fills chunks with items, and emits a chunk as []interface{} when it is closed,
+// or emits a window as *Observable when it is opened
+type bufferOperater struct {
+	window bool
+}
+
+func (bop bufferOperater) op(ctx context.Context, o *Observable) {
+	in := o.pred.outflow
+	out := o.outflow
+	spec := o.flip.(bufferSpec)
+
+	goFlow(ctx, func() {
+		var chunks []*chunk
+		var ticks <-chan time.Time
+		var timer *periodTimer
+		if spec.timespan > 0 {
+			timer = newPeriodTimer(ctx, spec.timespan)
+			defer timer.Stop()
+			ticks = timer.C()
+		}
+		end := false
+
+		// close the first k chunks, windows are always completed even if the flow ended
+		closeChunks := func(k int) {
+			for _, c := range chunks[:k] {
+				if bop.window {
+					c.window.OnCompleted()
+				} else if !end {
+					end = o.sendToFlow(ctx, c.items, out)
+				}
+			}
+			chunks = chunks[k:]
+		}
+
+		for i := 0; ; {
+			select {
+			case x, ok := <-in:
+				if !ok {
+					// flush on completion, but not on cancellation
+					if ctx.Err() != nil {
+						end = true
+					}
+					closeChunks(len(chunks))
+					o.closeFlow(ctx, out)
+					return
+				}
+				if end {
+					continue
+				}
+				if e, isErr := x.(error); isErr {
+					end = o.sendToFlow(ctx, e, out)
+					continue
+				}
+				// open a chunk lazily, so that no chunk is empty
+				if spec.timespan == 0 && i%spec.skip == 0 || spec.timespan > 0 && len(chunks) == 0 {
+					c := &chunk{}
+					if bop.window {
+						c.window = NewReplaySubject(0, 0)
+						c.window.Name = "window"
+						end = o.sendToFlow(ctx, c.window.Observable, out)
+					}
+					chunks = append(chunks, c)
+				}
+				i++
+				for _, c := range chunks {
+					c.n++
+					if bop.window {
+						c.window.OnNext(x)
+					} else {
+						c.items = append(c.items, x)
+					}
+				}
+				// only the oldest chunk may be full, and there is no chunk between skipped items
+				if spec.count > 0 && len(chunks) > 0 && chunks[0].n == spec.count {
+					closeChunks(1)
+					if timer != nil {
+						timer.restart()
+					}
+				}
+			case <-ticks:
+				timer.advance()
+				closeChunks(len(chunks))
+			}
+		}
+	})
+}
+
+func (parent *Observable) newBufferObservable(name string, spec bufferSpec, window bool) (o *Observable) {
+	if spec.skip <= 0 {
+		spec.skip = spec.count
+	}
+	o = parent.newTransformObservable(name)
+	o.timespan = spec.timespan
+	o.flip = spec
+	o.operator = bufferOperater{window}
+	return o
+}
+
+// BufferCount emits items in []interface{} of n items, and a new buffer starts every skip items.
+// Buffers overlap if skip < n, and items are dropped between buffers if skip > n. skip <= 0 means skip = n.
+// The buffers not full are emitted when the Observable completed
+func (parent *Observable) BufferCount(n, skip int) (o *Observable) {
+	if n <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("bufferCount", bufferSpec{count: n, skip: skip}, false)
+}
+
+// BufferTime emits items arrived in every timespan in []interface{}. No empty buffer is emitted
+func (parent *Observable) BufferTime(timespan time.Duration) (o *Observable) {
+	if timespan <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("bufferTime", bufferSpec{timespan: timespan}, false)
+}
+
+// BufferWithCountOrTime emits items in []interface{} when the buffer has n items or timespan elapsed,
+// whichever happens first. The timespan restarts when a full buffer is emitted
+func (parent *Observable) BufferWithCountOrTime(n int, timespan time.Duration) (o *Observable) {
+	if n <= 0 || timespan <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("bufferWithCountOrTime", bufferSpec{count: n, timespan: timespan}, false)
+}
+
+// WindowCount is like BufferCount, but emits each window as an *Observable when its first item arrives
+func (parent *Observable) WindowCount(n, skip int) (o *Observable) {
+	if n <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("windowCount", bufferSpec{count: n, skip: skip}, true)
+}
+
+// WindowTime is like BufferTime, but emits each window as an *Observable when its first item arrives
+func (parent *Observable) WindowTime(timespan time.Duration) (o *Observable) {
+	if timespan <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("windowTime", bufferSpec{timespan: timespan}, true)
+}
+
+// WindowWithCountOrTime is like BufferWithCountOrTime, but emits each window as an *Observable when its first item arrives
+func (parent *Observable) WindowWithCountOrTime(n int, timespan time.Duration) (o *Observable) {
+	if n <= 0 || timespan <= 0 {
+		panic(ErrFuncFlip)
+	}
+	return parent.newBufferObservable("windowWithCountOrTime", bufferSpec{count: n, timespan: timespan}, true)
+}
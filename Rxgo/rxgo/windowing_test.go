@@ -0,0 +1,142 @@
+package rxgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferCount(t *testing.T) {
+	res, completed := collectAll(Range(1, 8).BufferCount(3, 0))
+
+	assert.Equal(t, []interface{}{
+		[]interface{}{1, 2, 3}, []interface{}{4, 5, 6}, []interface{}{7},
+	}, res, "BufferCount Test Error!")
+	assert.True(t, completed, "BufferCount Completed Error!")
+
+	res, _ = collectAll(Range(1, 6).BufferCount(3, 1))
+	assert.Equal(t, []interface{}{
+		[]interface{}{1, 2, 3}, []interface{}{2, 3, 4}, []interface{}{3, 4, 5},
+		[]interface{}{4, 5}, []interface{}{5},
+	}, res, "BufferCount overlapped Test Error!")
+
+	res, _ = collectAll(Range(1, 8).BufferCount(2, 3))
+	assert.Equal(t, []interface{}{
+		[]interface{}{1, 2}, []interface{}{4, 5}, []interface{}{7},
+	}, res, "BufferCount skipped Test Error!")
+
+	ee := errors.New("Any")
+	res, _ = collectAll(Just(1, ee, 2, 3).BufferCount(2, 0))
+	assert.Equal(t, []interface{}{ee, []interface{}{1, 2}, []interface{}{3}}, res, "BufferCount error Test Error!")
+}
+
+func TestBufferTime(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  1,
+		3 * time.Millisecond:  2,
+		12 * time.Millisecond: 3,
+		35 * time.Millisecond: 4,
+	}, 38*time.Millisecond)
+	s := v.subscribe(source.BufferTime(10*time.Millisecond), ts)
+
+	ts.AdvanceBy(20 * time.Millisecond)
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}, []interface{}{3}}, v.items(), "BufferTime Test Error!")
+
+	// no empty buffer in (20, 30], and the last one is flushed on completion
+	ts.AdvanceBy(20 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}, []interface{}{3}, []interface{}{4}}, v.items(), "BufferTime flush Test Error!")
+}
+
+func TestBufferWithCountOrTime(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  1,
+		2 * time.Millisecond:  2,
+		3 * time.Millisecond:  3,
+		4 * time.Millisecond:  4,
+		15 * time.Millisecond: 5,
+	}, 50*time.Millisecond)
+	s := v.subscribe(source.BufferWithCountOrTime(3, 10*time.Millisecond), ts)
+
+	// full at 3ms restarts the timespan, which is over at 13ms
+	ts.AdvanceBy(12 * time.Millisecond)
+	assert.Equal(t, []interface{}{[]interface{}{1, 2, 3}}, v.items(), "BufferWithCountOrTime count Test Error!")
+	ts.AdvanceBy(2 * time.Millisecond)
+	assert.Equal(t, []interface{}{[]interface{}{1, 2, 3}, []interface{}{4}}, v.items(), "BufferWithCountOrTime time Test Error!")
+
+	ts.AdvanceBy(40 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{[]interface{}{1, 2, 3}, []interface{}{4}, []interface{}{5}}, v.items(), "BufferWithCountOrTime Test Error!")
+}
+
+func TestBufferCancel(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := Generator(func(ctx context.Context, send func(x interface{}) (endSignal bool)) {
+		send(1)
+		<-ctx.Done()
+	})
+	s := v.subscribe(source.BufferTime(10*time.Millisecond), ts)
+
+	ts.AdvanceBy(5 * time.Millisecond)
+	s.Dispose()
+	s.Wait()
+	assert.Len(t, v.items(), 0, "Buffer flushed on cancellation!")
+}
+
+func TestWindowCount(t *testing.T) {
+	res := [][]int{}
+	Range(1, 8).WindowCount(3, 0).Subscribe(func(w *Observable) {
+		items := []int{}
+		w.Subscribe(func(x int) {
+			items = append(items, x)
+		})
+		res = append(res, items)
+	})
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}, res, "WindowCount Test Error!")
+
+	res2, _ := collectAll(Range(1, 6).WindowCount(3, 2).FlatMap(func(w *Observable) *Observable {
+		return w.ToSlice()
+	}))
+	assert.Equal(t, []interface{}{
+		[]interface{}{1, 2, 3}, []interface{}{3, 4, 5}, []interface{}{5},
+	}, res2, "WindowCount overlapped Test Error!")
+}
+
+func TestWindowTime(t *testing.T) {
+	ts := NewTestScheduler()
+	var v virtualObserver
+	source := timedSource(map[time.Duration]interface{}{
+		1 * time.Millisecond:  1,
+		3 * time.Millisecond:  2,
+		12 * time.Millisecond: 3,
+		14 * time.Millisecond: 4,
+		15 * time.Millisecond: 5,
+	}, 30*time.Millisecond)
+	windows := source.WindowWithCountOrTime(2, 10*time.Millisecond).FlatMap(func(w *Observable) *Observable {
+		return w.Count()
+	})
+	s := v.subscribe(windows, ts)
+
+	ts.AdvanceBy(40 * time.Millisecond)
+	s.Wait()
+	// windows are closed by count at 3ms, by time at 13ms and by count at 15ms
+	assert.Equal(t, []interface{}{2, 1, 2}, v.items(), "WindowWithCountOrTime Test Error!")
+
+	var v2 virtualObserver
+	ts = NewTestScheduler()
+	s = v2.subscribe(source.WindowTime(10*time.Millisecond).FlatMap(func(w *Observable) *Observable {
+		return w.ToSlice()
+	}), ts)
+	ts.AdvanceBy(40 * time.Millisecond)
+	s.Wait()
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}, []interface{}{3, 4, 5}}, v2.items(), "WindowTime Test Error!")
+}